@@ -31,4 +31,45 @@ type config struct {
 	MaxDatabaseConnections int `envconfig:"max_connections" default:"10"`
 
 	StoredEventSenderDelay int `envconfig:"stored_event_sender_delay" default:"1"`
+
+	IdempotencyKeyTTL               int `envconfig:"idempotency_key_ttl" default:"24"`
+	IdempotencyRecordPurgeInterval  int `envconfig:"idempotency_record_purge_interval" default:"3600"`
+	IdempotencyRecordPurgeBatchSize int `envconfig:"idempotency_record_purge_batch_size" default:"1000"`
+
+	AuthorizationRulesPath string `envconfig:"authorization_rules_path"`
+
+	RateLimitRate         float64            `envconfig:"rate_limit_rate" default:"10"`
+	RateLimitBurst        int                `envconfig:"rate_limit_burst" default:"20"`
+	RateLimitMethodRates  map[string]float64 `envconfig:"rate_limit_method_rates"`
+	RateLimitMethodBursts map[string]int     `envconfig:"rate_limit_method_bursts"`
+
+	ContentCacheEnabled bool `envconfig:"content_cache_enabled" default:"true"`
+	ContentCacheSize    int  `envconfig:"content_cache_size" default:"10000"`
+	ContentCacheTTL     int  `envconfig:"content_cache_ttl" default:"300"`
+
+	ContentServiceCallTimeout             int    `envconfig:"content_service_call_timeout" default:"2000"`
+	ContentServiceMaxRetries              int    `envconfig:"content_service_max_retries" default:"2"`
+	ContentServiceRetryBackoff            int    `envconfig:"content_service_retry_backoff" default:"100"`
+	ContentServiceBreakerFailureThreshold int    `envconfig:"content_service_breaker_failure_threshold" default:"5"`
+	ContentServiceBreakerOpenTimeout      int    `envconfig:"content_service_breaker_open_timeout" default:"30"`
+	ContentCheckFallback                  string `envconfig:"content_check_fallback" default:"reject"`
+
+	PendingContentVerificationInterval  int `envconfig:"pending_content_verification_interval" default:"60"`
+	PendingContentVerificationBatchSize int `envconfig:"pending_content_verification_batch_size" default:"100"`
+
+	ContentReconciliationInterval  int `envconfig:"content_reconciliation_interval" default:"3600"`
+	ContentReconciliationBatchSize int `envconfig:"content_reconciliation_batch_size" default:"100"`
+
+	DataExportInterval  int `envconfig:"data_export_interval" default:"5"`
+	DataExportBatchSize int `envconfig:"data_export_batch_size" default:"10"`
+
+	ReadModelRebuildBatchSize int `envconfig:"read_model_rebuild_batch_size" default:"100"`
+
+	PlaylistReadFlushInterval  int     `envconfig:"playlist_read_flush_interval" default:"10"`
+	TrendingRefreshInterval    int     `envconfig:"trending_refresh_interval" default:"300"`
+	TrendingWindow             int     `envconfig:"trending_window" default:"72"`
+	TrendingHalfLife           int     `envconfig:"trending_half_life" default:"24"`
+	TrendingReadWeight         float64 `envconfig:"trending_read_weight" default:"1"`
+	TrendingItemAdditionWeight float64 `envconfig:"trending_item_addition_weight" default:"5"`
+	TrendingSize               int     `envconfig:"trending_size" default:"500"`
 }
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"expvar"
 	"io"
 	stdlog "log"
 	"net/http"
@@ -21,14 +21,17 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/connectivity"
 
 	contentserviceapi "playlistservice/api/contentservice"
 	"playlistservice/api/playlistservice"
 	migrationsembedder "playlistservice/data/mysql"
+	"playlistservice/pkg/playlistservice/app/service"
 	"playlistservice/pkg/playlistservice/infrastructure"
+	"playlistservice/pkg/playlistservice/infrastructure/authorization"
 	"playlistservice/pkg/playlistservice/infrastructure/integrationevent"
 	"playlistservice/pkg/playlistservice/infrastructure/mysql"
+	mysqlservice "playlistservice/pkg/playlistservice/infrastructure/mysql/service"
+	infrastructureservice "playlistservice/pkg/playlistservice/infrastructure/service"
 	"playlistservice/pkg/playlistservice/infrastructure/transport"
 )
 
@@ -45,6 +48,14 @@ func main() {
 		logger.FatalError(err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == rebuildReadModelCommand {
+		err = rebuildReadModel(config, logger)
+		if err != nil {
+			logger.FatalError(err)
+		}
+		return
+	}
+
 	err = runService(config, logger)
 	if err == server.ErrStopped {
 		logger.Info("service is successfully stopped")
@@ -54,18 +65,7 @@ func main() {
 }
 
 func runService(config *config, logger log.MainLogger) error {
-	dsn := commonmysql.DSN{
-		User:     config.DatabaseUser,
-		Password: config.DatabasePassword,
-		Host:     config.DatabaseHost,
-		Database: config.DatabaseName,
-	}
-	connector := commonmysql.NewConnector()
-	err := connector.MigrateUp(dsn, migrationsembedder.MigrationsEmbedder)
-	if err != nil {
-		logger.Error(err, "failed to migrate")
-	}
-	err = connector.Open(dsn, config.MaxDatabaseConnections)
+	connector, err := openDatabase(config, logger)
 	if err != nil {
 		return err
 	}
@@ -87,6 +87,15 @@ func runService(config *config, logger log.MainLogger) error {
 
 	transactionalClient := connector.TransactionalClient()
 
+	// playlists created before read model table are absent in it until they change, so they are projected before serving
+	projected, err := playlistProjector(transactionalClient, config.ReadModelRebuildBatchSize).ProjectUnprojected()
+	if err != nil {
+		return err
+	}
+	if projected > 0 {
+		logger.WithField("projected_playlists", projected).Info("unprojected playlists added to read model")
+	}
+
 	integrationEventTransport := integrationevent.NewIntegrationEventTransport()
 	amqpConnection.AddChannel(integrationEventTransport)
 
@@ -102,20 +111,76 @@ func runService(config *config, logger log.MainLogger) error {
 
 	defer storedEventSender.Stop()
 
+	playlistProjectionSender := initPlaylistProjectionSender(
+		transactionalClient,
+		eventStore,
+		logger,
+		time.Duration(config.StoredEventSenderDelay)*time.Second,
+		config.ReadModelRebuildBatchSize,
+	)
+
+	defer playlistProjectionSender.Stop()
+
 	contentServiceClient, err := initContentServiceClient(config)
 	if err != nil {
 		return err
 	}
 
+	fallback, err := contentCheckFallback(config)
+	if err != nil {
+		return err
+	}
+
+	authorizationRules, err := authorization.LoadRules(config.AuthorizationRulesPath)
+	if err != nil {
+		return err
+	}
+
 	container := infrastructure.NewDependencyContainer(
 		connector.TransactionalClient(),
 		logger,
 		contentServiceClient,
 		eventStore,
-		storedEventSender.Increment,
+		func() {
+			storedEventSender.Increment()
+			playlistProjectionSender.Increment()
+		},
+		infrastructure.Config{
+			IdempotencyKeyTTL:               time.Duration(config.IdempotencyKeyTTL) * time.Hour,
+			IdempotencyRecordPurgeBatchSize: config.IdempotencyRecordPurgeBatchSize,
+			AuthorizationRules:              authorizationRules,
+			ContentCache: infrastructureservice.ContentCacheConfig{
+				Enabled: config.ContentCacheEnabled,
+				Size:    config.ContentCacheSize,
+				TTL:     time.Duration(config.ContentCacheTTL) * time.Second,
+			},
+			ContentService: infrastructureservice.ResilienceConfig{
+				CallTimeout:             time.Duration(config.ContentServiceCallTimeout) * time.Millisecond,
+				MaxRetries:              config.ContentServiceMaxRetries,
+				RetryBackoff:            time.Duration(config.ContentServiceRetryBackoff) * time.Millisecond,
+				BreakerFailureThreshold: config.ContentServiceBreakerFailureThreshold,
+				BreakerOpenTimeout:      time.Duration(config.ContentServiceBreakerOpenTimeout) * time.Second,
+			},
+			ContentCheckFallback:                fallback,
+			PendingContentVerificationBatchSize: config.PendingContentVerificationBatchSize,
+			ContentReconciliationBatchSize:      config.ContentReconciliationBatchSize,
+			DataExportBatchSize:                 config.DataExportBatchSize,
+			Trending: service.TrendingConfig{
+				Window:             time.Duration(config.TrendingWindow) * time.Hour,
+				HalfLife:           time.Duration(config.TrendingHalfLife) * time.Hour,
+				ReadWeight:         config.TrendingReadWeight,
+				ItemAdditionWeight: config.TrendingItemAdditionWeight,
+				Size:               config.TrendingSize,
+			},
+		},
 	)
 
+	expvar.Publish("content_cache", expvar.Func(func() interface{} {
+		return container.ContentCacheStats()
+	}))
+
 	integrationEventTransport.SetHandler(container.IntegrationEventHandler())
+	integrationEventTransport.SetInstanceHandler(container.ContentCacheInvalidationHandler())
 
 	err = amqpConnection.Start()
 	if err != nil {
@@ -128,11 +193,13 @@ func runService(config *config, logger log.MainLogger) error {
 		}
 	}()
 
-	serviceAPI := transport.NewPlaylistServiceServer(container)
+	serviceAPI := transport.NewPlaylistServiceServer(container, logger)
+	adminServiceAPI := transport.NewPlaylistAdminServiceServer(container, logger)
 	serverHub := server.NewHub(stopChan)
 
-	baseServer := grpc.NewServer(grpc.UnaryInterceptor(makeGRPCUnaryInterceptor(logger)))
+	baseServer := grpc.NewServer(grpc.UnaryInterceptor(makeGRPCUnaryInterceptor(logger, config, container)))
 	playlistservice.RegisterPlayListServiceServer(baseServer, serviceAPI)
+	playlistservice.RegisterPlaylistAdminServiceServer(baseServer, adminServiceAPI)
 
 	serverHub.AddServer(server.NewGrpcServer(
 		baseServer,
@@ -140,18 +207,117 @@ func runService(config *config, logger log.MainLogger) error {
 		logger),
 	)
 
+	if fallback == service.ContentCheckFallbackPendingVerification {
+		serverHub.AddServer(periodicTaskServer(
+			time.Duration(config.PendingContentVerificationInterval)*time.Second,
+			func() {
+				verifyErr := container.PendingContentVerifier().VerifyPendingContent()
+				if errors.Cause(verifyErr) == commonmysql.ErrLockTimeout {
+					logger.Info("pending content verification is running by another instance")
+					return
+				}
+				if verifyErr != nil {
+					logger.Error(verifyErr, "failed to verify pending content")
+				}
+			},
+		))
+	}
+
+	serverHub.AddServer(periodicTaskServer(
+		time.Duration(config.ContentReconciliationInterval)*time.Second,
+		func() {
+			report, reconcileErr := container.ContentReconciler().Reconcile()
+			if errors.Cause(reconcileErr) == commonmysql.ErrLockTimeout {
+				logger.Info("content reconciliation is running by another instance")
+				return
+			}
+			reportLogger := logger.WithFields(log.Fields{
+				"checked_content":     report.CheckedContent,
+				"available_content":   report.AvailableContent,
+				"unavailable_content": report.UnavailableContent,
+				"missing_content":     report.MissingContent,
+				"completed":           report.Completed,
+			})
+			if reconcileErr != nil {
+				reportLogger.Error(reconcileErr, "failed to reconcile content")
+				return
+			}
+			reportLogger.Info("content reconciled")
+		},
+	))
+
+	serverHub.AddServer(periodicTaskServer(
+		time.Duration(config.DataExportInterval)*time.Second,
+		func() {
+			completed, exportErr := container.DataExportService().ProcessPendingExports()
+			if exportErr != nil {
+				logger.Error(exportErr, "failed to process data exports")
+			}
+			if completed > 0 {
+				logger.WithField("completed_exports", completed).Info("data exports completed")
+			}
+		},
+	))
+
+	serverHub.AddServer(periodicTaskServer(
+		time.Duration(config.IdempotencyRecordPurgeInterval)*time.Second,
+		func() {
+			purged, purgeErr := container.IdempotencyRecordPurger().Purge()
+			if purgeErr != nil {
+				logger.Error(purgeErr, "failed to purge expired idempotency records")
+			}
+			if purged > 0 {
+				logger.WithField("purged_records", purged).Info("expired idempotency records purged")
+			}
+		},
+	))
+
+	serverHub.AddServer(periodicTaskServer(
+		time.Duration(config.PlaylistReadFlushInterval)*time.Second,
+		func() {
+			flushErr := container.PlaylistReadCounter().Flush()
+			if flushErr != nil {
+				logger.Error(flushErr, "failed to store playlist reads")
+			}
+		},
+	))
+
+	serverHub.AddServer(periodicTaskServer(
+		time.Duration(config.TrendingRefreshInterval)*time.Second,
+		func() {
+			ranked, refreshErr := container.TrendingRanker().Refresh()
+			if errors.Cause(refreshErr) == commonmysql.ErrLockTimeout {
+				logger.Info("trending playlists refresh is running by another instance")
+				return
+			}
+			if refreshErr != nil {
+				logger.Error(refreshErr, "failed to refresh trending playlists")
+				return
+			}
+			logger.WithField("ranked_playlists", ranked).Info("trending playlists refreshed")
+		},
+	))
+
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 	var httpServer *http.Server
 
 	serverHub.AddServer(&server.FuncServer{
 		ServeImpl: func() error {
-			grpcGatewayMux := runtime.NewServeMux()
+			grpcGatewayMux := runtime.NewServeMux(
+				runtime.WithOutgoingHeaderMatcher(transport.GatewayOutgoingHeaderMatcher),
+				runtime.WithForwardResponseOption(transport.GatewayNotModifiedResponseOption),
+				runtime.WithProtoErrorHandler(transport.GatewayProtoErrorHandler),
+			)
 			opts := []grpc.DialOption{grpc.WithInsecure()}
 			err2 := playlistservice.RegisterPlayListServiceHandlerFromEndpoint(ctx, grpcGatewayMux, config.ServeGRPCAddress, opts)
 			if err2 != nil {
 				return err2
 			}
+			err2 = playlistservice.RegisterPlaylistAdminServiceHandlerFromEndpoint(ctx, grpcGatewayMux, config.ServeGRPCAddress, opts)
+			if err2 != nil {
+				return err2
+			}
 
 			router := mux.NewRouter()
 			router.PathPrefix("/api/").Handler(grpcGatewayMux)
@@ -161,6 +327,10 @@ func runService(config *config, logger log.MainLogger) error {
 				_, _ = io.WriteString(w, http.StatusText(http.StatusOK))
 			}).Methods(http.MethodGet)
 
+			router.Handle("/debug/vars", expvar.Handler()).Methods(http.MethodGet)
+
+			router.Handle(transport.DataExportDownloadPath, transport.NewDataExportDownloadHandler(container, logger)).Methods(http.MethodGet)
+
 			httpServer = &http.Server{
 				Handler:      transport.NewLoggingMiddleware(router, logger),
 				Addr:         config.ServeRESTAddress,
@@ -193,29 +363,44 @@ func listenForKillSignal(stopChan chan<- struct{}) {
 	}()
 }
 
-func makeGRPCUnaryInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+func makeGRPCUnaryInterceptor(logger log.Logger, config *config, container infrastructure.DependencyContainer) grpc.UnaryServerInterceptor {
 	loggerInterceptor := transport.NewLoggerServerInterceptor(logger)
+	rateLimitInterceptor := transport.NewRateLimitServerInterceptor(rateLimitConfig(config), container.UserDescriptorSerializer())
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		resp, err = loggerInterceptor(ctx, req, info, handler)
+		resp, err = loggerInterceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return rateLimitInterceptor(ctx, req, info, handler)
+		})
 		return resp, err
 	}
 }
 
+func rateLimitConfig(config *config) transport.RateLimitConfig {
+	methods := make(map[string]transport.RateLimit, len(config.RateLimitMethodRates))
+	for method, rate := range config.RateLimitMethodRates {
+		burst, ok := config.RateLimitMethodBursts[method]
+		if !ok {
+			burst = config.RateLimitBurst
+		}
+		methods[method] = transport.RateLimit{Rate: rate, Burst: burst}
+	}
+
+	return transport.RateLimitConfig{
+		Default: transport.RateLimit{Rate: config.RateLimitRate, Burst: config.RateLimitBurst},
+		Methods: methods,
+	}
+}
+
 func initContentServiceClient(config *config) (contentserviceapi.ContentServiceClient, error) {
 	opts := []grpc.DialOption{
 		grpc.WithInsecure(),
 	}
 
+	// dial doesn't wait for connection, so service starts even when content service is down
 	conn, err := grpc.Dial(config.ContentServiceGRPCAddress, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	err = waitForConnectionReady(conn)
-	if err != nil {
-		return nil, err
-	}
-
 	return contentserviceapi.NewContentServiceClient(conn), nil
 }
 
@@ -237,15 +422,74 @@ func initStoredEventSender(
 	)
 }
 
-func waitForConnectionReady(conn *grpc.ClientConn) error {
-	const retries = 30
+func initPlaylistProjectionSender(
+	client commonmysql.TransactionalClient,
+	eventStore storedevent.Store,
+	logger log.Logger,
+	delay time.Duration,
+	batchSize int,
+) storedevent.Sender {
+	return storedevent.NewStoredEventSender(
+		eventStore,
+		mysql.NewProjectionTracker(client),
+		playlistProjector(client, batchSize),
+		delay,
+		func(err error) { logger.Error(err, "failed to project playlists") },
+	)
+}
 
-	for i := 0; i < retries; i++ {
-		if conn.GetState() == connectivity.Ready {
-			return nil
-		}
-		time.Sleep(time.Second)
+func playlistProjector(client commonmysql.TransactionalClient, batchSize int) service.PlaylistProjector {
+	return service.NewPlaylistProjector(mysqlservice.NewPlaylistReadModelStorage(client), batchSize)
+}
+
+func openDatabase(config *config, logger log.Logger) (commonmysql.Connector, error) {
+	dsn := commonmysql.DSN{
+		User:     config.DatabaseUser,
+		Password: config.DatabasePassword,
+		Host:     config.DatabaseHost,
+		Database: config.DatabaseName,
+	}
+	connector := commonmysql.NewConnector()
+	err := connector.MigrateUp(dsn, migrationsembedder.MigrationsEmbedder)
+	if err != nil {
+		logger.Error(err, "failed to migrate")
 	}
+	err = connector.Open(dsn, config.MaxDatabaseConnections)
+	if err != nil {
+		return nil, err
+	}
+	return connector, nil
+}
 
-	return errors.New(fmt.Sprintf("failed to wait service %s", conn.Target()))
+func contentCheckFallback(config *config) (service.ContentCheckFallback, error) {
+	fallback := service.ContentCheckFallback(config.ContentCheckFallback)
+	switch fallback {
+	case service.ContentCheckFallbackReject, service.ContentCheckFallbackPendingVerification:
+		return fallback, nil
+	default:
+		return "", errors.Errorf("unknown content check fallback %s", config.ContentCheckFallback)
+	}
+}
+
+func periodicTaskServer(interval time.Duration, task func()) server.Server {
+	stopChan := make(chan struct{})
+	return &server.FuncServer{
+		ServeImpl: func() error {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					task()
+				case <-stopChan:
+					return nil
+				}
+			}
+		},
+		StopImpl: func() error {
+			close(stopChan)
+			return nil
+		},
+	}
 }
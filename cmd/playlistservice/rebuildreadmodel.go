@@ -0,0 +1,29 @@
+package main
+
+import (
+	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
+)
+
+// rebuildReadModelCommand projects all playlists to read model, run it after read model is created or got out of sync
+const rebuildReadModelCommand = "rebuild-read-model"
+
+func rebuildReadModel(config *config, logger log.MainLogger) error {
+	connector, err := openDatabase(config, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeConnectorErr := connector.Close()
+		if closeConnectorErr != nil {
+			logger.Error(closeConnectorErr, "failed to close database connection")
+		}
+	}()
+
+	projected, err := playlistProjector(connector.TransactionalClient(), config.ReadModelRebuildBatchSize).Rebuild()
+	if err != nil {
+		return err
+	}
+
+	logger.WithField("projected_playlists", projected).Info("read model rebuilt")
+	return nil
+}
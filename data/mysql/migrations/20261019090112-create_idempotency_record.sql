@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE idempotency_record
+(
+    `idempotency_key` VARCHAR(255) NOT NULL,
+    `user_id` binary(16) NOT NULL,
+    `request_hash` CHAR(64) NOT NULL,
+    `response` VARCHAR(1000) NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`idempotency_key`, `user_id`)
+);
+-- +migrate Down
+DROP TABLE idempotency_record;
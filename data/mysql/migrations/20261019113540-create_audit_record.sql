@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE audit_record
+(
+    `audit_record_id` BIGINT NOT NULL AUTO_INCREMENT,
+    `playlist_id` binary(16) NOT NULL,
+    `owner_id` binary(16) NOT NULL,
+    `actor_id` binary(16) NOT NULL,
+    `command` VARCHAR(255) NOT NULL,
+    `diff` TEXT NOT NULL,
+    `request_id` VARCHAR(255) NOT NULL,
+    `source_ip` VARCHAR(255) NOT NULL,
+    `user_agent` VARCHAR(255) NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`audit_record_id`),
+    INDEX `playlist_id_index` (`playlist_id`)
+);
+-- +migrate Down
+DROP TABLE audit_record;
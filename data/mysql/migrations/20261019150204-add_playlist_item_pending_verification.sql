@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE playlist_item
+    ADD COLUMN `pending_verification` TINYINT(1) NOT NULL DEFAULT 0,
+    ADD INDEX `pending_verification_index` (`pending_verification`);
+-- +migrate Down
+ALTER TABLE playlist_item
+    DROP INDEX `pending_verification_index`,
+    DROP COLUMN `pending_verification`;
@@ -0,0 +1,19 @@
+-- +migrate Up
+-- availability is 0 for available, 1 for pending verification and 2 for unavailable items
+ALTER TABLE playlist_item
+    ADD COLUMN `availability` TINYINT NOT NULL DEFAULT 0,
+    ADD INDEX `availability_index` (`availability`);
+UPDATE playlist_item SET `availability` = 1 WHERE `pending_verification` = 1;
+ALTER TABLE playlist_item
+    DROP INDEX `pending_verification_index`,
+    DROP COLUMN `pending_verification`;
+-- +migrate Down
+ALTER TABLE playlist_item
+    ADD COLUMN `pending_verification` TINYINT(1) NOT NULL DEFAULT 0,
+    ADD INDEX `pending_verification_index` (`pending_verification`);
+UPDATE playlist_item SET `pending_verification` = 1 WHERE `availability` = 1;
+-- unavailable items were removed before availability states existed
+DELETE FROM playlist_item WHERE `availability` = 2;
+ALTER TABLE playlist_item
+    DROP INDEX `availability_index`,
+    DROP COLUMN `availability`;
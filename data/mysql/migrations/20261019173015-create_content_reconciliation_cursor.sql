@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE content_reconciliation_cursor
+(
+    `name` VARCHAR(255) NOT NULL,
+    `last_content_id` binary(16) NULL,
+    `updated_at` datetime NOT NULL,
+    PRIMARY KEY (`name`)
+);
+-- +migrate Down
+DROP TABLE content_reconciliation_cursor;
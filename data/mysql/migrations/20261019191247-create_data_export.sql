@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE data_export
+(
+    `data_export_id` binary(16) NOT NULL,
+    `user_id` binary(16) NOT NULL,
+    `include_csv` TINYINT(1) NOT NULL,
+    `status` TINYINT NOT NULL,
+    `archive` LONGBLOB NULL,
+    `created_at` datetime NOT NULL,
+    `completed_at` datetime NULL,
+    PRIMARY KEY (`data_export_id`),
+    INDEX `status_index` (`status`, `created_at`)
+);
+-- +migrate Down
+DROP TABLE data_export;
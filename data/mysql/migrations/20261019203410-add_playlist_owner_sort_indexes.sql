@@ -0,0 +1,10 @@
+-- +migrate Up
+ALTER TABLE playlist
+    ADD INDEX `owner_id_created_at_index` (`owner_id`, `created_at`, `playlist_id`),
+    ADD INDEX `owner_id_updated_at_index` (`owner_id`, `updated_at`, `playlist_id`),
+    ADD INDEX `owner_id_name_index` (`owner_id`, `name`, `playlist_id`);
+-- +migrate Down
+ALTER TABLE playlist
+    DROP INDEX `owner_id_created_at_index`,
+    DROP INDEX `owner_id_updated_at_index`,
+    DROP INDEX `owner_id_name_index`;
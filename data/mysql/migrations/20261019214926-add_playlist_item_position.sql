@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE playlist_item
+    ADD COLUMN `position` BIGINT NOT NULL DEFAULT 0;
+-- existing items are numbered in order they were added, ties are broken by id to keep numbering stable
+SET @position := 0;
+UPDATE playlist_item SET `position` = (@position := @position + 1) ORDER BY `created_at`, `playlist_item_id`;
+ALTER TABLE playlist_item
+    MODIFY COLUMN `position` BIGINT NOT NULL AUTO_INCREMENT,
+    ADD UNIQUE INDEX `position_index` (`position`),
+    ADD INDEX `playlist_id_position_index` (`playlist_id`, `position`),
+    ADD INDEX `playlist_id_created_at_index` (`playlist_id`, `created_at`);
+-- +migrate Down
+ALTER TABLE playlist_item
+    DROP INDEX `playlist_id_created_at_index`,
+    DROP INDEX `playlist_id_position_index`,
+    DROP INDEX `position_index`,
+    DROP COLUMN `position`;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE playlist
+    ADD FULLTEXT INDEX `name_fulltext_index` (`name`);
+-- +migrate Down
+ALTER TABLE playlist
+    DROP INDEX `name_fulltext_index`;
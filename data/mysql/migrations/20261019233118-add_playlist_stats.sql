@@ -0,0 +1,18 @@
+-- +migrate Up
+ALTER TABLE playlist
+    ADD COLUMN `item_count` INT NOT NULL DEFAULT 0,
+    ADD COLUMN `distinct_content_count` INT NOT NULL DEFAULT 0,
+    ADD COLUMN `last_item_added_at` datetime NULL,
+    ADD INDEX `owner_id_item_count_index` (`owner_id`, `item_count`, `playlist_id`);
+
+UPDATE playlist p
+SET
+    p.item_count = (SELECT COUNT(*) FROM playlist_item pi WHERE pi.playlist_id = p.playlist_id),
+    p.distinct_content_count = (SELECT COUNT(DISTINCT pi.content_id) FROM playlist_item pi WHERE pi.playlist_id = p.playlist_id),
+    p.last_item_added_at = (SELECT MAX(pi.created_at) FROM playlist_item pi WHERE pi.playlist_id = p.playlist_id);
+-- +migrate Down
+ALTER TABLE playlist
+    DROP INDEX `owner_id_item_count_index`,
+    DROP COLUMN `last_item_added_at`,
+    DROP COLUMN `distinct_content_count`,
+    DROP COLUMN `item_count`;
@@ -0,0 +1,14 @@
+-- +migrate Up
+ALTER TABLE stored_event
+    ADD COLUMN `sequence` BIGINT NULL;
+
+SET @sequence = 0;
+UPDATE stored_event SET `sequence` = (@sequence := @sequence + 1) ORDER BY created_at;
+
+ALTER TABLE stored_event
+    MODIFY COLUMN `sequence` BIGINT NOT NULL AUTO_INCREMENT,
+    ADD UNIQUE INDEX `sequence_index` (`sequence`);
+-- +migrate Down
+ALTER TABLE stored_event
+    DROP INDEX `sequence_index`,
+    DROP COLUMN `sequence`;
@@ -0,0 +1,25 @@
+-- +migrate Up
+CREATE TABLE playlist_read_model
+(
+    `playlist_id` binary(16) NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `owner_id` binary(16) NOT NULL,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `item_count` INT NOT NULL,
+    `distinct_content_count` INT NOT NULL,
+    `last_item_added_at` datetime NULL,
+    `items` MEDIUMTEXT NOT NULL,
+    PRIMARY KEY (`playlist_id`),
+    INDEX `owner_id_created_at_index` (`owner_id`, `created_at`, `playlist_id`),
+    INDEX `owner_id_updated_at_index` (`owner_id`, `updated_at`, `playlist_id`),
+    INDEX `owner_id_name_index` (`owner_id`, `name`, `playlist_id`),
+    INDEX `owner_id_item_count_index` (`owner_id`, `item_count`, `playlist_id`)
+);
+
+-- projector starts after existing events, existing playlists are projected by service on startup
+INSERT INTO tracked_stored_event (transport_name, last_stored_event_id, created_at)
+SELECT 'playlist_read_model_projector', stored_event_id, now() FROM stored_event ORDER BY `sequence` DESC LIMIT 1;
+-- +migrate Down
+DELETE FROM tracked_stored_event WHERE transport_name = 'playlist_read_model_projector';
+DROP TABLE playlist_read_model;
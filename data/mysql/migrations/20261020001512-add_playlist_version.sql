@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE playlist
+    ADD COLUMN `version` INT NOT NULL DEFAULT 1;
+
+ALTER TABLE playlist_read_model
+    ADD COLUMN `version` INT NOT NULL DEFAULT 1;
+-- +migrate Down
+ALTER TABLE playlist_read_model
+    DROP COLUMN `version`;
+
+ALTER TABLE playlist
+    DROP COLUMN `version`;
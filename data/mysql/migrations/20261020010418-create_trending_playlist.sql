@@ -0,0 +1,36 @@
+-- +migrate Up
+ALTER TABLE playlist
+    ADD COLUMN `discoverable` TINYINT(1) NOT NULL DEFAULT 0;
+
+-- item additions are counted from stored events of trending window
+ALTER TABLE stored_event
+    ADD INDEX `type_created_at_index` (`type`, `created_at`);
+
+CREATE TABLE playlist_read_activity
+(
+    `playlist_id` binary(16) NOT NULL,
+    `hour_start` datetime NOT NULL,
+    `read_count` INT NOT NULL,
+    PRIMARY KEY (`playlist_id`, `hour_start`),
+    INDEX `hour_start_index` (`hour_start`)
+);
+
+-- content_types is bit mask of content types in playlist, bit number is content type
+CREATE TABLE trending_playlist
+(
+    `position` INT NOT NULL,
+    `playlist_id` binary(16) NOT NULL,
+    `score` DOUBLE NOT NULL,
+    `content_types` INT NOT NULL,
+    PRIMARY KEY (`position`),
+    UNIQUE INDEX `playlist_id_index` (`playlist_id`)
+);
+-- +migrate Down
+DROP TABLE trending_playlist;
+DROP TABLE playlist_read_activity;
+
+ALTER TABLE stored_event
+    DROP INDEX `type_created_at_index`;
+
+ALTER TABLE playlist
+    DROP COLUMN `discoverable`;
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE playlist_read_model
+    ADD COLUMN `discoverable` TINYINT(1) NOT NULL DEFAULT 0;
+
+-- discoverability changes made before column existed are copied from playlist table
+UPDATE playlist_read_model rm
+    INNER JOIN playlist p ON p.playlist_id = rm.playlist_id
+SET rm.discoverable = p.discoverable;
+-- +migrate Down
+ALTER TABLE playlist_read_model
+    DROP COLUMN `discoverable`;
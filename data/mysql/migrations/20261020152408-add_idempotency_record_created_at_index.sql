@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE idempotency_record
+    ADD INDEX `created_at_index` (`created_at`);
+-- +migrate Down
+ALTER TABLE idempotency_record
+    DROP INDEX `created_at_index`;
@@ -53,6 +53,8 @@ func addToPlaylist(playlistServiceAPI PlaylistServiceAPI, contentServiceAPI Cont
 		assertNoErr(err)
 
 		assertEqual(1, len(playlistResp.PlaylistItems))
+		assertEqual(int32(1), playlistResp.ItemCount)
+		assertEqual(int32(1), playlistResp.DistinctContentCount)
 		assertEqual(playlistItemID, playlistResp.PlaylistItems[0].PlaylistItemID)
 		assertEqual(publicContentID, playlistResp.PlaylistItems[0].ContentID)
 
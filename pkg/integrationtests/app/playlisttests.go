@@ -38,6 +38,7 @@ func createPlaylist(playlistServiceAPI PlaylistServiceAPI) {
 		assertNoErr(err)
 
 		assertEqual(2, len(playlists.Playlists))
+		assertEqual(int32(2), playlists.TotalCount)
 
 		secondPlaylist, err := playlistServiceAPI.GetPlaylist(secondPlaylistID, user)
 		assertNoErr(err)
@@ -0,0 +1,32 @@
+package query
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditRecordView struct {
+	ID         int64
+	PlaylistID uuid.UUID
+	OwnerID    uuid.UUID
+	ActorID    uuid.UUID
+	Command    string
+	Diff       string
+	RequestID  string
+	SourceIP   string
+	UserAgent  string
+	CreatedAt  time.Time
+}
+
+// AuditLogSpecification selects page of playlist audit records ordered from newest,
+// AfterID excludes records up to the last record of previous page
+type AuditLogSpecification struct {
+	PlaylistID uuid.UUID
+	AfterID    *int64
+	Limit      int
+}
+
+type AuditLogQueryService interface {
+	GetAuditLog(spec AuditLogSpecification) ([]AuditRecordView, error)
+}
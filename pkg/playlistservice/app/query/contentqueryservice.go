@@ -0,0 +1,35 @@
+package query
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ContentType int
+
+const (
+	ContentTypeSong ContentType = iota
+	ContentTypePodcast
+)
+
+type ContentAvailabilityType int
+
+const (
+	ContentAvailabilityTypePublic ContentAvailabilityType = iota
+	ContentAvailabilityTypePrivate
+)
+
+type ContentView struct {
+	ID               uuid.UUID
+	Name             string
+	AuthorID         uuid.UUID
+	Type             ContentType
+	AvailabilityType ContentAvailabilityType
+}
+
+// ContentQueryService reads content details owned by content service
+type ContentQueryService interface {
+	// GetContents returns found content only, so caller must handle absent ids
+	GetContents(ctx context.Context, contentIDs []uuid.UUID) ([]ContentView, error)
+}
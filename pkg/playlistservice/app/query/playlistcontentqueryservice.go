@@ -0,0 +1,13 @@
+package query
+
+import (
+	"github.com/google/uuid"
+)
+
+// PlaylistContentQueryService looks up playlists by content of their items
+type PlaylistContentQueryService interface {
+	// GetOwnerPlaylistsContainingContent maps content to playlists of owner containing it, absent content is in no playlist
+	GetOwnerPlaylistsContainingContent(ownerID uuid.UUID, contentIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
+	// CountPlaylistsContainingContent maps content to count of all playlists containing it, absent content is in no playlist
+	CountPlaylistsContainingContent(contentIDs []uuid.UUID) (map[uuid.UUID]int, error)
+}
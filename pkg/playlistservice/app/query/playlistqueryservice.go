@@ -6,26 +6,122 @@ import (
 	"github.com/google/uuid"
 )
 
+// PlaylistView contains stats of all playlist items even when only part of items is loaded
 type PlaylistView struct {
-	ID            uuid.UUID
-	Name          string
-	OwnerID       uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	PlaylistItems []PlaylistItemView
+	ID                   uuid.UUID
+	Name                 string
+	OwnerID              uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Version              int
+	ItemCount            int
+	DistinctContentCount int
+	LastItemAddedAt      *time.Time
+	Discoverable         bool
+	PlaylistItems        []PlaylistItemView
 }
 
 type PlaylistItemView struct {
+	ID                  uuid.UUID
+	ContentID           uuid.UUID
+	PendingVerification bool
+	Unavailable         bool
+	CreatedAt           *time.Time
+	// Position is order of addition, items added later have greater position
+	Position int64
+}
+
+type PlaylistSortField int
+
+const (
+	PlaylistSortByCreatedAt PlaylistSortField = iota
+	PlaylistSortByName
+	PlaylistSortByUpdatedAt
+	PlaylistSortByItemCount
+)
+
+// PlaylistSort orders playlists by field, ties are ordered by playlist id in the same direction
+type PlaylistSort struct {
+	Field      PlaylistSortField
+	Descending bool
+}
+
+// PlaylistCursor points to last playlist of previous page, pages stay stable under concurrent inserts
+// since next page starts right after sort key of cursor instead of offset
+type PlaylistCursor struct {
 	ID        uuid.UUID
-	ContentID uuid.UUID
-	CreatedAt *time.Time
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ItemCount int
+}
+
+func NewPlaylistCursor(view PlaylistView) PlaylistCursor {
+	return PlaylistCursor{
+		ID:        view.ID,
+		Name:      view.Name,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: view.UpdatedAt,
+		ItemCount: view.ItemCount,
+	}
 }
 
+// NoItems as ItemsLimit loads playlists without items
+const NoItems = -1
+
+// PlaylistSpecification selects playlists, zero Limit returns all matched playlists after cursor.
+// ItemsLimit limits items loaded for each playlist in position order, zero loads all items.
+// SharedPlaylistIDs extends OwnerIDs by playlists of other owners
 type PlaylistSpecification struct {
-	PlaylistIDs []uuid.UUID
-	OwnerIDs    []uuid.UUID
+	PlaylistIDs       []uuid.UUID
+	OwnerIDs          []uuid.UUID
+	SharedPlaylistIDs []uuid.UUID
+	Sort              PlaylistSort
+	After             *PlaylistCursor
+	Limit             int
+	ItemsLimit        int
+}
+
+type PlaylistItemSortField int
+
+const (
+	PlaylistItemSortByPosition PlaylistItemSortField = iota
+	PlaylistItemSortByCreatedAt
+)
+
+type PlaylistItemSort struct {
+	Field      PlaylistItemSortField
+	Descending bool
+}
+
+// PlaylistItemCursor points to last item of previous page like PlaylistCursor
+type PlaylistItemCursor struct {
+	ID        uuid.UUID
+	Position  int64
+	CreatedAt time.Time
+}
+
+func NewPlaylistItemCursor(view PlaylistItemView) PlaylistItemCursor {
+	cursor := PlaylistItemCursor{
+		ID:       view.ID,
+		Position: view.Position,
+	}
+	if view.CreatedAt != nil {
+		cursor.CreatedAt = *view.CreatedAt
+	}
+	return cursor
+}
+
+type PlaylistItemSpecification struct {
+	PlaylistID uuid.UUID
+	Sort       PlaylistItemSort
+	After      *PlaylistItemCursor
+	Limit      int
 }
 
 type PlaylistQueryService interface {
 	GetPlaylists(spec PlaylistSpecification) ([]PlaylistView, error)
+	// CountPlaylists counts all playlists matched by specification ignoring cursor and limit
+	CountPlaylists(spec PlaylistSpecification) (int, error)
+	GetPlaylistItems(spec PlaylistItemSpecification) ([]PlaylistItemView, error)
 }
@@ -0,0 +1,30 @@
+package query
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlaylistSearchSpecification filters found playlists, nil date bounds are not applied
+type PlaylistSearchSpecification struct {
+	Text          string
+	OwnerIDs      []uuid.UUID
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Offset        int
+	Limit         int
+}
+
+type PlaylistSearchResult struct {
+	Playlist  PlaylistView
+	Relevance float64
+}
+
+type PlaylistSearchService interface {
+	// SearchPlaylists finds playlists which names contain every word of text as prefix,
+	// results are ordered by relevance and loaded without items
+	SearchPlaylists(spec PlaylistSearchSpecification) ([]PlaylistSearchResult, error)
+}
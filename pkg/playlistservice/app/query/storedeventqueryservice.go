@@ -0,0 +1,19 @@
+package query
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StoredEventView struct {
+	ID        uuid.UUID
+	Type      string
+	Body      string
+	CreatedAt time.Time
+}
+
+type StoredEventQueryService interface {
+	// GetUserEvents returns events about user and playlists ever owned by user ordered from oldest
+	GetUserEvents(userID uuid.UUID) ([]StoredEventView, error)
+}
@@ -0,0 +1,23 @@
+package query
+
+// TrendingPlaylistSpecification selects page of trending playlists after position of previous page,
+// nil ContentType matches playlists of any content
+type TrendingPlaylistSpecification struct {
+	ContentType   *ContentType
+	AfterPosition int
+	Limit         int
+	ItemsLimit    int
+}
+
+type TrendingPlaylistView struct {
+	Playlist PlaylistView
+	// Position is rank of playlist in whole ranking starting from 1
+	Position int
+	Score    float64
+}
+
+type TrendingPlaylistQueryService interface {
+	// GetTrendingPlaylists returns playlists ordered by rank, playlists which owners opted out
+	// after last ranking refresh are skipped
+	GetTrendingPlaylists(spec TrendingPlaylistSpecification) ([]TrendingPlaylistView, error)
+}
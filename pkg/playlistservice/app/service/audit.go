@@ -0,0 +1,208 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+// CommandMetadata describes request which issued command
+type CommandMetadata struct {
+	IdempotencyKey string
+	RequestID      string
+	SourceIP       string
+	UserAgent      string
+	// ExpectedPlaylistVersion is version of playlist client based command on, zero skips the check
+	ExpectedPlaylistVersion int
+}
+
+type AuditRecord struct {
+	PlaylistID uuid.UUID
+	OwnerID    uuid.UUID
+	ActorID    uuid.UUID
+	Command    string
+	Diff       string
+	RequestID  string
+	SourceIP   string
+	UserAgent  string
+	CreatedAt  time.Time
+}
+
+type AuditRecordRepository interface {
+	Store(record AuditRecord) error
+}
+
+type valueChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type auditItem struct {
+	PlaylistItemID uuid.UUID `json:"playlist_item_id"`
+	ContentID      uuid.UUID `json:"content_id"`
+}
+
+type auditItemAvailability struct {
+	PlaylistItemID uuid.UUID `json:"playlist_item_id"`
+	ContentID      uuid.UUID `json:"content_id"`
+	Before         string    `json:"before"`
+	After          string    `json:"after"`
+}
+
+type playlistDiff struct {
+	Created                  bool                    `json:"created,omitempty"`
+	Removed                  bool                    `json:"removed,omitempty"`
+	Name                     *valueChange            `json:"name,omitempty"`
+	Discoverable             *valueChange            `json:"discoverable,omitempty"`
+	AddedItems               []auditItem             `json:"added_items,omitempty"`
+	RemovedItems             []auditItem             `json:"removed_items,omitempty"`
+	AvailabilityChangedItems []auditItemAvailability `json:"availability_changed_items,omitempty"`
+}
+
+var auditAvailabilities = map[domain.PlaylistItemAvailability]string{
+	domain.PlaylistItemAvailable:           "available",
+	domain.PlaylistItemPendingVerification: "pending_verification",
+	domain.PlaylistItemUnavailable:         "unavailable",
+}
+
+func diffPlaylists(before, after *domain.Playlist) playlistDiff {
+	diff := playlistDiff{
+		Created: before == nil && after != nil,
+		Removed: before != nil && after == nil,
+	}
+
+	beforeItems := map[domain.PlaylistItemID]domain.PlaylistItem{}
+	afterItems := map[domain.PlaylistItemID]domain.PlaylistItem{}
+	var beforeName, afterName string
+	var beforeDiscoverable, afterDiscoverable bool
+
+	if before != nil {
+		beforeItems = before.Items()
+		beforeName = before.Name()
+		beforeDiscoverable = before.Discoverable()
+	}
+	if after != nil {
+		afterItems = after.Items()
+		afterName = after.Name()
+		afterDiscoverable = after.Discoverable()
+	}
+
+	if beforeName != afterName {
+		diff.Name = &valueChange{Before: beforeName, After: afterName}
+	}
+
+	if beforeDiscoverable != afterDiscoverable {
+		diff.Discoverable = &valueChange{Before: strconv.FormatBool(beforeDiscoverable), After: strconv.FormatBool(afterDiscoverable)}
+	}
+
+	for id, item := range afterItems {
+		beforeItem, ok := beforeItems[id]
+		if !ok {
+			diff.AddedItems = append(diff.AddedItems, auditItem{PlaylistItemID: uuid.UUID(id), ContentID: uuid.UUID(item.ContentID())})
+			continue
+		}
+		if beforeItem.Availability() != item.Availability() {
+			diff.AvailabilityChangedItems = append(diff.AvailabilityChangedItems, auditItemAvailability{
+				PlaylistItemID: uuid.UUID(id),
+				ContentID:      uuid.UUID(item.ContentID()),
+				Before:         auditAvailabilities[beforeItem.Availability()],
+				After:          auditAvailabilities[item.Availability()],
+			})
+		}
+	}
+
+	for id, item := range beforeItems {
+		if _, ok := afterItems[id]; !ok {
+			diff.RemovedItems = append(diff.RemovedItems, auditItem{PlaylistItemID: uuid.UUID(id), ContentID: uuid.UUID(item.ContentID())})
+		}
+	}
+
+	return diff
+}
+
+// NewAuditMiddleware stores audit record with playlist diff for each playlist changed by committed command,
+// batch commands get record per touched playlist, must be placed after unit of work middleware to share its transaction
+func NewAuditMiddleware() CommandMiddleware {
+	return func(next CommandHandlerFunc) CommandHandlerFunc {
+		return func(ctx *CommandContext) (CommandResult, error) {
+			if _, ok := ctx.Command.(PlaylistCommand); !ok {
+				result, err := next(ctx)
+				if err != nil {
+					return CommandResult{}, err
+				}
+
+				for _, change := range ctx.playlistChanges {
+					before := change.Before
+					err = storeAuditRecord(ctx, &before, change.After)
+					if err != nil {
+						return CommandResult{}, err
+					}
+				}
+				return result, nil
+			}
+
+			playlist, err := ctx.Playlist()
+			if err != nil {
+				return CommandResult{}, err
+			}
+
+			// handler changes loaded playlist, so its state before command is kept apart
+			var before *domain.Playlist
+			if playlist != nil {
+				snapshot := playlist.Clone()
+				before = &snapshot
+			}
+
+			result, err := next(ctx)
+			if err != nil {
+				return CommandResult{}, err
+			}
+
+			after, err := ctx.Playlist()
+			if err != nil {
+				return CommandResult{}, err
+			}
+
+			return result, storeAuditRecord(ctx, before, after)
+		}
+	}
+}
+
+func storeAuditRecord(ctx *CommandContext, before, after *domain.Playlist) error {
+	record, ok, err := newAuditRecord(ctx, before, after)
+	if err != nil || !ok {
+		return err
+	}
+	return ctx.Provider.AuditRecordRepository().Store(record)
+}
+
+func newAuditRecord(ctx *CommandContext, before, after *domain.Playlist) (AuditRecord, bool, error) {
+	playlist := after
+	if playlist == nil {
+		playlist = before
+	}
+	if playlist == nil {
+		return AuditRecord{}, false, nil
+	}
+
+	diff, err := json.Marshal(diffPlaylists(before, after))
+	if err != nil {
+		return AuditRecord{}, false, err
+	}
+
+	return AuditRecord{
+		PlaylistID: uuid.UUID(playlist.ID()),
+		OwnerID:    uuid.UUID(playlist.OwnerID()),
+		ActorID:    ctx.Command.Actor().UserID,
+		Command:    ctx.Command.CommandName(),
+		Diff:       string(diff),
+		RequestID:  ctx.Metadata.RequestID,
+		SourceIP:   ctx.Metadata.SourceIP,
+		UserAgent:  ctx.Metadata.UserAgent,
+		CreatedAt:  time.Now(),
+	}, true, nil
+}
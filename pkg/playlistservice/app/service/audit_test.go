@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	ownerID := uuid.New()
+
+	{
+		playlist, err := domain.NewPlaylist(domain.PlaylistID(uuid.New()), "magic", domain.PlaylistOwnerID(ownerID))
+		assert.NoError(t, err)
+
+		playlistRepo := &mockPlaylistRepository{playlist: playlist}
+		auditRepo := &mockAuditRecordRepository{}
+		provider := &mockPlaylistRepositoryProvider{repo: playlistRepo, auditRepo: auditRepo}
+
+		handler := NewPlaylistVersionMiddleware()(NewAuditMiddleware()(func(ctx *CommandContext) (CommandResult, error) {
+			repo := ctx.PlaylistRepository()
+			playlist, err := repo.Find(playlist.ID())
+			if err != nil {
+				return CommandResult{}, err
+			}
+			playlist.SetName(ctx.Command.(SetPlaylistNameCommand).NewName)
+			return CommandResult{}, repo.Store(playlist)
+		}))
+
+		_, err = handler(&CommandContext{
+			Command: SetPlaylistNameCommand{
+				PlaylistID:     uuid.UUID(playlist.ID()),
+				UserDescriptor: auth.UserDescriptor{UserID: ownerID},
+				NewName:        "new magic",
+			},
+			Metadata: CommandMetadata{ExpectedPlaylistVersion: playlist.Version()},
+			Provider: provider,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, playlistRepo.finds, "playlist is loaded once for middlewares and handler")
+		assert.Equal(t, "new magic", playlistRepo.playlist.Name())
+
+		assert.Equal(t, 1, len(auditRepo.records))
+		assert.Equal(t, uuid.UUID(playlist.ID()), auditRepo.records[0].PlaylistID)
+		assert.Equal(t, ownerID, auditRepo.records[0].ActorID)
+		assert.JSONEq(t, `{"name":{"before":"magic","after":"new magic"}}`, auditRepo.records[0].Diff)
+	}
+
+	{
+		contentID := domain.ContentID(uuid.New())
+		playlistItemID := domain.PlaylistItemID(uuid.New())
+
+		playlist, err := domain.NewPlaylist(domain.PlaylistID(uuid.New()), "magic", domain.PlaylistOwnerID(ownerID))
+		assert.NoError(t, err)
+		playlist.AddItem(playlistItemID, contentID, domain.PlaylistItemAvailable)
+
+		before := playlist.Clone()
+		playlist.SetContentAvailability(contentID, domain.PlaylistItemUnavailable)
+
+		removedPlaylist, err := domain.NewPlaylist(domain.PlaylistID(uuid.New()), "removed magic", domain.PlaylistOwnerID(ownerID))
+		assert.NoError(t, err)
+
+		auditRepo := &mockAuditRecordRepository{}
+		provider := &mockPlaylistRepositoryProvider{auditRepo: auditRepo}
+
+		handler := NewAuditMiddleware()(func(ctx *CommandContext) (CommandResult, error) {
+			ctx.playlistChanges = []domain.PlaylistChange{
+				{Before: before, After: &playlist},
+				{Before: removedPlaylist},
+			}
+			return CommandResult{Affected: len(ctx.playlistChanges)}, nil
+		})
+
+		result, err := handler(&CommandContext{
+			Command:  SetContentAvailabilityCommand{ContentIDs: []uuid.UUID{uuid.UUID(contentID)}, BatchSize: 10},
+			Provider: provider,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Affected)
+
+		assert.Equal(t, 2, len(auditRepo.records), "batch command gets record per touched playlist")
+		assert.Equal(t, uuid.UUID(playlist.ID()), auditRepo.records[0].PlaylistID)
+		assert.JSONEq(t, `{"availability_changed_items":[{
+			"playlist_item_id":"`+uuid.UUID(playlistItemID).String()+`",
+			"content_id":"`+uuid.UUID(contentID).String()+`",
+			"before":"available",
+			"after":"unavailable"
+		}]}`, auditRepo.records[0].Diff)
+		assert.Equal(t, uuid.UUID(removedPlaylist.ID()), auditRepo.records[1].PlaylistID)
+		assert.JSONEq(t, `{"removed":true,"name":{"before":"removed magic","after":""}}`, auditRepo.records[1].Diff)
+	}
+}
+
+type mockAuditRecordRepository struct {
+	records []AuditRecord
+}
+
+func (repo *mockAuditRecordRepository) Store(record AuditRecord) error {
+	repo.records = append(repo.records, record)
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+var (
+	ErrCommandHandlerNotFound = errors.New("command handler not found")
+)
+
+type Command interface {
+	CommandName() string
+	Actor() auth.UserDescriptor
+	// LockName returns name of lock held by unit of work while command executes
+	LockName() string
+	// Payload returns command arguments that identify request for idempotency checks
+	Payload() []string
+}
+
+// PlaylistCommand is implemented by commands which change single playlist
+type PlaylistCommand interface {
+	Command
+	FindPlaylist(repo domain.PlaylistRepository) (domain.Playlist, error)
+}
+
+// CommandResult holds ID of entity created by command, if any
+type CommandResult struct {
+	ID uuid.UUID
+	// Affected is count of entities changed by batch command
+	Affected int
+}
+
+type CommandContext struct {
+	Command  Command
+	Metadata CommandMetadata
+	// Provider is set by unit of work middleware
+	Provider RepositoryProvider
+
+	// playlist of PlaylistCommand is loaded once and shared by middlewares and handler, nil when it doesn't exist
+	playlist       *domain.Playlist
+	playlistLoaded bool
+	// playlistChanges are set by handlers of batch commands
+	playlistChanges []domain.PlaylistChange
+}
+
+// Playlist returns playlist of PlaylistCommand, it's loaded in unit of work once and reflects changes stored by handler,
+// nil is returned when playlist doesn't exist
+func (ctx *CommandContext) Playlist() (*domain.Playlist, error) {
+	if ctx.playlistLoaded {
+		return ctx.playlist, nil
+	}
+
+	playlist, err := ctx.Command.(PlaylistCommand).FindPlaylist(ctx.Provider.PlaylistRepository())
+	if err != nil && err != domain.ErrPlaylistNotFound && err != domain.ErrPlaylistByItemNotFound {
+		return nil, err
+	}
+	if err == nil {
+		ctx.playlist = &playlist
+	}
+	ctx.playlistLoaded = true
+
+	return ctx.playlist, nil
+}
+
+// PlaylistRepository returns playlist repository of unit of work which serves playlist loaded by Playlist
+func (ctx *CommandContext) PlaylistRepository() domain.PlaylistRepository {
+	return &commandPlaylistRepository{
+		PlaylistRepository: ctx.Provider.PlaylistRepository(),
+		ctx:                ctx,
+	}
+}
+
+type commandPlaylistRepository struct {
+	domain.PlaylistRepository
+	ctx *CommandContext
+}
+
+func (repo *commandPlaylistRepository) Find(id domain.PlaylistID) (domain.Playlist, error) {
+	if playlist := repo.ctx.playlist; playlist != nil && playlist.ID() == id {
+		return *playlist, nil
+	}
+	return repo.PlaylistRepository.Find(id)
+}
+
+func (repo *commandPlaylistRepository) FindByItemID(playlistItemID domain.PlaylistItemID) (domain.Playlist, error) {
+	if playlist := repo.ctx.playlist; playlist != nil {
+		if _, ok := playlist.Items()[playlistItemID]; ok {
+			return *playlist, nil
+		}
+	}
+	return repo.PlaylistRepository.FindByItemID(playlistItemID)
+}
+
+func (repo *commandPlaylistRepository) Store(playlist domain.Playlist) error {
+	err := repo.PlaylistRepository.Store(playlist)
+	if err != nil {
+		return err
+	}
+
+	if repo.ctx.playlistLoaded && (repo.ctx.playlist == nil || repo.ctx.playlist.ID() == playlist.ID()) {
+		repo.ctx.playlist = &playlist
+	}
+	return nil
+}
+
+func (repo *commandPlaylistRepository) Remove(id domain.PlaylistID) error {
+	err := repo.PlaylistRepository.Remove(id)
+	if err != nil {
+		return err
+	}
+
+	if repo.ctx.playlist != nil && repo.ctx.playlist.ID() == id {
+		repo.ctx.playlist = nil
+	}
+	return nil
+}
+
+type CommandHandlerFunc func(ctx *CommandContext) (CommandResult, error)
+
+// CommandMiddleware wraps next handler in pipeline, it may run code around next or skip it at all
+type CommandMiddleware func(next CommandHandlerFunc) CommandHandlerFunc
+
+type CommandBus interface {
+	Dispatch(command Command, metadata CommandMetadata) (CommandResult, error)
+}
+
+// NewCommandBus creates bus that passes commands through middlewares in given order, first middleware is outermost
+func NewCommandBus(handlers map[string]CommandHandlerFunc, middlewares ...CommandMiddleware) CommandBus {
+	return &commandBus{
+		handlers:    handlers,
+		middlewares: middlewares,
+	}
+}
+
+type commandBus struct {
+	handlers    map[string]CommandHandlerFunc
+	middlewares []CommandMiddleware
+}
+
+func (bus *commandBus) Dispatch(command Command, metadata CommandMetadata) (CommandResult, error) {
+	handler, ok := bus.handlers[command.CommandName()]
+	if !ok {
+		return CommandResult{}, errors.Wrap(ErrCommandHandlerNotFound, command.CommandName())
+	}
+
+	for i := len(bus.middlewares) - 1; i >= 0; i-- {
+		handler = bus.middlewares[i](handler)
+	}
+
+	return handler(&CommandContext{
+		Command:  command,
+		Metadata: metadata,
+	})
+}
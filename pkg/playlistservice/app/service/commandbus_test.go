@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandBus_Dispatch(t *testing.T) {
+	var calls []string
+
+	middleware := func(name string) CommandMiddleware {
+		return func(next CommandHandlerFunc) CommandHandlerFunc {
+			return func(ctx *CommandContext) (CommandResult, error) {
+				calls = append(calls, name+" before")
+				result, err := next(ctx)
+				calls = append(calls, name+" after")
+				return result, err
+			}
+		}
+	}
+
+	playlistID := uuid.New()
+
+	bus := NewCommandBus(map[string]CommandHandlerFunc{
+		createPlaylistCommandName: func(ctx *CommandContext) (CommandResult, error) {
+			calls = append(calls, "handler "+ctx.Command.(CreatePlaylistCommand).Name)
+			assert.Equal(t, "request-id", ctx.Metadata.RequestID)
+			return CommandResult{ID: playlistID}, nil
+		},
+	}, middleware("first"), middleware("second"))
+
+	{
+		result, err := bus.Dispatch(CreatePlaylistCommand{
+			Name:           "magic",
+			UserDescriptor: auth.UserDescriptor{UserID: uuid.New()},
+		}, CommandMetadata{RequestID: "request-id"})
+		assert.NoError(t, err)
+		assert.Equal(t, playlistID, result.ID)
+
+		assert.Equal(t, []string{
+			"first before",
+			"second before",
+			"handler magic",
+			"second after",
+			"first after",
+		}, calls, "middlewares wrap handler in given order")
+	}
+
+	{
+		_, err := bus.Dispatch(RemovePlaylistCommand{PlaylistID: uuid.New()}, CommandMetadata{})
+		assert.Equal(t, ErrCommandHandlerNotFound, errors.Cause(err))
+	}
+}
+
+func TestCommandResult_Serialization(t *testing.T) {
+	{
+		result := CommandResult{ID: uuid.New()}
+
+		response, err := serializeCommandResult(result)
+		assert.NoError(t, err)
+		assert.Equal(t, `"`+result.ID.String()+`"`, response)
+
+		deserialized, err := deserializeCommandResult(response)
+		assert.NoError(t, err)
+		assert.Equal(t, result, deserialized)
+	}
+
+	{
+		response, err := serializeCommandResult(CommandResult{})
+		assert.NoError(t, err)
+		assert.Equal(t, "null", response)
+
+		deserialized, err := deserializeCommandResult(response)
+		assert.NoError(t, err)
+		assert.Equal(t, CommandResult{}, deserialized)
+	}
+}
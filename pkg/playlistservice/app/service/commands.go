@@ -0,0 +1,257 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+const (
+	createPlaylistCommandName          = "create_playlist"
+	setPlaylistNameCommandName         = "set_playlist_name"
+	setPlaylistDiscoverableCommandName = "set_playlist_discoverable"
+	addToPlaylistCommandName           = "add_to_playlist"
+	removeFromPlaylistCommandName      = "remove_from_playlist"
+	removePlaylistCommandName          = "remove_playlist"
+
+	removeOwnerPlaylistsCommandName   = "remove_owner_playlists"
+	removeContentCommandName          = "remove_content"
+	setContentAvailabilityCommandName = "set_content_availability"
+)
+
+type CreatePlaylistCommand struct {
+	Name           string
+	UserDescriptor auth.UserDescriptor
+}
+
+func (command CreatePlaylistCommand) CommandName() string {
+	return createPlaylistCommandName
+}
+
+func (command CreatePlaylistCommand) Actor() auth.UserDescriptor {
+	return command.UserDescriptor
+}
+
+// LockName excludes removal of owner playlists, so playlist is not created while owner is being deleted
+func (command CreatePlaylistCommand) LockName() string {
+	return ownerLockName + command.UserDescriptor.UserID.String()
+}
+
+func (command CreatePlaylistCommand) Payload() []string {
+	return []string{command.Name}
+}
+
+// FindPlaylist reports missing playlist, playlist doesn't exist until command creates it
+func (command CreatePlaylistCommand) FindPlaylist(domain.PlaylistRepository) (domain.Playlist, error) {
+	return domain.Playlist{}, domain.ErrPlaylistNotFound
+}
+
+type SetPlaylistNameCommand struct {
+	PlaylistID     uuid.UUID
+	NewName        string
+	UserDescriptor auth.UserDescriptor
+}
+
+func (command SetPlaylistNameCommand) CommandName() string {
+	return setPlaylistNameCommandName
+}
+
+func (command SetPlaylistNameCommand) Actor() auth.UserDescriptor {
+	return command.UserDescriptor
+}
+
+func (command SetPlaylistNameCommand) LockName() string {
+	return playlistLockName + command.PlaylistID.String()
+}
+
+func (command SetPlaylistNameCommand) Payload() []string {
+	return []string{command.PlaylistID.String(), command.NewName}
+}
+
+func (command SetPlaylistNameCommand) FindPlaylist(repo domain.PlaylistRepository) (domain.Playlist, error) {
+	return repo.Find(domain.PlaylistID(command.PlaylistID))
+}
+
+type SetPlaylistDiscoverableCommand struct {
+	PlaylistID     uuid.UUID
+	Discoverable   bool
+	UserDescriptor auth.UserDescriptor
+}
+
+func (command SetPlaylistDiscoverableCommand) CommandName() string {
+	return setPlaylistDiscoverableCommandName
+}
+
+func (command SetPlaylistDiscoverableCommand) Actor() auth.UserDescriptor {
+	return command.UserDescriptor
+}
+
+func (command SetPlaylistDiscoverableCommand) LockName() string {
+	return playlistLockName + command.PlaylistID.String()
+}
+
+func (command SetPlaylistDiscoverableCommand) Payload() []string {
+	return []string{command.PlaylistID.String(), strconv.FormatBool(command.Discoverable)}
+}
+
+func (command SetPlaylistDiscoverableCommand) FindPlaylist(repo domain.PlaylistRepository) (domain.Playlist, error) {
+	return repo.Find(domain.PlaylistID(command.PlaylistID))
+}
+
+type AddToPlaylistCommand struct {
+	PlaylistID          uuid.UUID
+	ContentID           uuid.UUID
+	PendingVerification bool
+	UserDescriptor      auth.UserDescriptor
+}
+
+func (command AddToPlaylistCommand) CommandName() string {
+	return addToPlaylistCommandName
+}
+
+func (command AddToPlaylistCommand) Actor() auth.UserDescriptor {
+	return command.UserDescriptor
+}
+
+func (command AddToPlaylistCommand) LockName() string {
+	return playlistLockName + command.PlaylistID.String()
+}
+
+func (command AddToPlaylistCommand) Payload() []string {
+	return []string{command.PlaylistID.String(), command.ContentID.String()}
+}
+
+func (command AddToPlaylistCommand) FindPlaylist(repo domain.PlaylistRepository) (domain.Playlist, error) {
+	return repo.Find(domain.PlaylistID(command.PlaylistID))
+}
+
+type RemoveFromPlaylistCommand struct {
+	PlaylistItemID uuid.UUID
+	UserDescriptor auth.UserDescriptor
+}
+
+func (command RemoveFromPlaylistCommand) CommandName() string {
+	return removeFromPlaylistCommandName
+}
+
+func (command RemoveFromPlaylistCommand) Actor() auth.UserDescriptor {
+	return command.UserDescriptor
+}
+
+func (command RemoveFromPlaylistCommand) LockName() string {
+	return playlistLockName + command.PlaylistItemID.String()
+}
+
+func (command RemoveFromPlaylistCommand) Payload() []string {
+	return []string{command.PlaylistItemID.String()}
+}
+
+func (command RemoveFromPlaylistCommand) FindPlaylist(repo domain.PlaylistRepository) (domain.Playlist, error) {
+	return repo.FindByItemID(domain.PlaylistItemID(command.PlaylistItemID))
+}
+
+type RemovePlaylistCommand struct {
+	PlaylistID     uuid.UUID
+	UserDescriptor auth.UserDescriptor
+}
+
+func (command RemovePlaylistCommand) CommandName() string {
+	return removePlaylistCommandName
+}
+
+func (command RemovePlaylistCommand) Actor() auth.UserDescriptor {
+	return command.UserDescriptor
+}
+
+func (command RemovePlaylistCommand) LockName() string {
+	return playlistLockName + command.PlaylistID.String()
+}
+
+func (command RemovePlaylistCommand) Payload() []string {
+	return []string{command.PlaylistID.String()}
+}
+
+func (command RemovePlaylistCommand) FindPlaylist(repo domain.PlaylistRepository) (domain.Playlist, error) {
+	return repo.Find(domain.PlaylistID(command.PlaylistID))
+}
+
+// RemoveOwnerPlaylistsCommand removes single batch of owner playlists, it's issued by service itself on user deletion
+type RemoveOwnerPlaylistsCommand struct {
+	OwnerID   uuid.UUID
+	BatchSize int
+}
+
+func (command RemoveOwnerPlaylistsCommand) CommandName() string {
+	return removeOwnerPlaylistsCommandName
+}
+
+func (command RemoveOwnerPlaylistsCommand) Actor() auth.UserDescriptor {
+	return auth.UserDescriptor{}
+}
+
+// LockName excludes playlist creation by owner, playlists of batch are locked by repository when they are loaded
+func (command RemoveOwnerPlaylistsCommand) LockName() string {
+	return ownerLockName + command.OwnerID.String()
+}
+
+func (command RemoveOwnerPlaylistsCommand) Payload() []string {
+	return []string{command.OwnerID.String()}
+}
+
+// RemoveContentCommand removes content from single batch of playlists, it's issued by service itself on content changes
+type RemoveContentCommand struct {
+	ContentIDs []uuid.UUID
+	BatchSize  int
+}
+
+func (command RemoveContentCommand) CommandName() string {
+	return removeContentCommandName
+}
+
+func (command RemoveContentCommand) Actor() auth.UserDescriptor {
+	return auth.UserDescriptor{}
+}
+
+// LockName excludes other content batches only, playlists of batch are locked by repository when they are loaded
+func (command RemoveContentCommand) LockName() string {
+	return contentSyncLockName
+}
+
+func (command RemoveContentCommand) Payload() []string {
+	return uuidsToStrings(command.ContentIDs)
+}
+
+// SetContentAvailabilityCommand changes single batch of playlists, it's issued by service itself on content changes
+type SetContentAvailabilityCommand struct {
+	ContentIDs []uuid.UUID
+	Available  bool
+	BatchSize  int
+}
+
+func (command SetContentAvailabilityCommand) CommandName() string {
+	return setContentAvailabilityCommandName
+}
+
+func (command SetContentAvailabilityCommand) Actor() auth.UserDescriptor {
+	return auth.UserDescriptor{}
+}
+
+// LockName excludes other content batches only, playlists of batch are locked by repository when they are loaded
+func (command SetContentAvailabilityCommand) LockName() string {
+	return contentSyncLockName
+}
+
+func (command SetContentAvailabilityCommand) Payload() []string {
+	return append(uuidsToStrings(command.ContentIDs), strconv.FormatBool(command.Available))
+}
+
+func uuidsToStrings(ids []uuid.UUID) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, id.String())
+	}
+	return result
+}
@@ -1,14 +1,62 @@
 package service
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
 var (
-	ErrContentNotFound = errors.New("content not found")
+	ErrContentNotFound           = errors.New("content not found")
+	ErrContentServiceUnavailable = errors.New("content service unavailable")
+)
+
+// ContentCheckFallback decides what happens to added content when content service is unavailable
+type ContentCheckFallback string
+
+const (
+	ContentCheckFallbackReject              ContentCheckFallback = "reject"
+	ContentCheckFallbackPendingVerification ContentCheckFallback = "pending_verification"
 )
 
+// ContentNotFoundError lists requested content that doesn't exist or can't be added to playlists
+type ContentNotFoundError struct {
+	MissingContentIDs     []uuid.UUID
+	UnavailableContentIDs []uuid.UUID
+}
+
+func (err *ContentNotFoundError) Error() string {
+	var parts []string
+	if len(err.MissingContentIDs) != 0 {
+		parts = append(parts, fmt.Sprintf("missing %s", joinUUIDs(err.MissingContentIDs)))
+	}
+	if len(err.UnavailableContentIDs) != 0 {
+		parts = append(parts, fmt.Sprintf("not available %s", joinUUIDs(err.UnavailableContentIDs)))
+	}
+	return fmt.Sprintf("%s: %s", ErrContentNotFound, strings.Join(parts, ", "))
+}
+
+func (err *ContentNotFoundError) Is(target error) bool {
+	return target == ErrContentNotFound
+}
+
 type ContentChecker interface {
+	// ContentExists returns *ContentNotFoundError when any of contentIDs can't be added to playlist
+	// and ErrContentServiceUnavailable when content can't be checked at all
 	ContentExists(contentIDs []uuid.UUID) error
 }
+
+// ContentCache keeps results of content checks, entries are dropped when content changes
+type ContentCache interface {
+	Invalidate(contentIDs []uuid.UUID)
+}
+
+func joinUUIDs(ids []uuid.UUID) string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, id.String())
+	}
+	return strings.Join(result, ", ")
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// ContentReconciliationTracker keeps reconciliation cursor, its lock makes runs exclusive across service instances
+type ContentReconciliationTracker interface {
+	Lock() error
+	Unlock() error
+	Cursor() (*uuid.UUID, error)
+	SaveCursor(cursor *uuid.UUID) error
+}
+
+type ContentReconciliationReport struct {
+	CheckedContent     int
+	AvailableContent   int
+	UnavailableContent int
+	MissingContent     int
+	// Completed is false when run stopped before last content, next run continues from saved cursor
+	Completed bool
+}
+
+type ContentReconciler interface {
+	// Reconcile checks all content referenced by playlists and handles it like content integration events
+	Reconcile() (ContentReconciliationReport, error)
+}
+
+func NewContentReconciler(
+	checker ContentChecker,
+	cache ContentCache,
+	storage PlaylistContentStorage,
+	tracker ContentReconciliationTracker,
+	playlistService PlaylistService,
+	batchSize int,
+) ContentReconciler {
+	return &contentReconciler{
+		checker:         checker,
+		cache:           cache,
+		storage:         storage,
+		tracker:         tracker,
+		playlistService: playlistService,
+		batchSize:       batchSize,
+	}
+}
+
+type contentReconciler struct {
+	checker         ContentChecker
+	cache           ContentCache
+	storage         PlaylistContentStorage
+	tracker         ContentReconciliationTracker
+	playlistService PlaylistService
+	batchSize       int
+}
+
+func (reconciler *contentReconciler) Reconcile() (report ContentReconciliationReport, err error) {
+	err = reconciler.tracker.Lock()
+	if err != nil {
+		return report, err
+	}
+	defer func() {
+		unlockErr := reconciler.tracker.Unlock()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	cursor, err := reconciler.tracker.Cursor()
+	if err != nil {
+		return report, err
+	}
+
+	for {
+		contentIDs, err2 := reconciler.storage.ContentIDs(cursor, reconciler.batchSize)
+		if err2 != nil {
+			return report, err2
+		}
+
+		if len(contentIDs) != 0 {
+			result, err3 := checkContent(reconciler.checker, contentIDs)
+			if err3 != nil {
+				return report, err3
+			}
+
+			// cached checks may be stale when content event was lost
+			reconciler.cache.Invalidate(append(append([]uuid.UUID{}, result.missing...), result.unavailable...))
+
+			err3 = applyContentCheck(reconciler.playlistService, result)
+			if err3 != nil {
+				return report, err3
+			}
+
+			report.CheckedContent += len(contentIDs)
+			report.AvailableContent += len(result.available)
+			report.UnavailableContent += len(result.unavailable)
+			report.MissingContent += len(result.missing)
+
+			cursor = &contentIDs[len(contentIDs)-1]
+		}
+
+		if len(contentIDs) < reconciler.batchSize {
+			// start from the beginning on next run
+			report.Completed = true
+			return report, reconciler.tracker.SaveCursor(nil)
+		}
+
+		err2 = reconciler.tracker.SaveCursor(cursor)
+		if err2 != nil {
+			return report, err2
+		}
+	}
+}
+
+// contentCheckResult splits checked content by the way playlists should handle it
+type contentCheckResult struct {
+	available   []uuid.UUID
+	unavailable []uuid.UUID
+	missing     []uuid.UUID
+}
+
+func checkContent(checker ContentChecker, contentIDs []uuid.UUID) (contentCheckResult, error) {
+	err := checker.ContentExists(contentIDs)
+	if err == nil {
+		return contentCheckResult{available: contentIDs}, nil
+	}
+
+	var contentNotFoundErr *ContentNotFoundError
+	if !errors.As(err, &contentNotFoundErr) {
+		return contentCheckResult{}, err
+	}
+
+	return contentCheckResult{
+		available:   excludeUUIDs(excludeUUIDs(contentIDs, contentNotFoundErr.MissingContentIDs), contentNotFoundErr.UnavailableContentIDs),
+		unavailable: contentNotFoundErr.UnavailableContentIDs,
+		missing:     contentNotFoundErr.MissingContentIDs,
+	}, nil
+}
+
+func applyContentCheck(playlistService PlaylistService, result contentCheckResult) error {
+	err := playlistService.RemoveFromPlaylists(result.missing)
+	if err != nil {
+		return err
+	}
+
+	err = playlistService.SetContentAvailability(result.unavailable, false)
+	if err != nil {
+		return err
+	}
+
+	return playlistService.SetContentAvailability(result.available, true)
+}
+
+func excludeUUIDs(ids []uuid.UUID, excluded []uuid.UUID) []uuid.UUID {
+	excludedSet := make(map[uuid.UUID]struct{}, len(excluded))
+	for _, id := range excluded {
+		excludedSet[id] = struct{}{}
+	}
+
+	var result []uuid.UUID
+	for _, id := range ids {
+		if _, ok := excludedSet[id]; !ok {
+			result = append(result, id)
+		}
+	}
+	return result
+}
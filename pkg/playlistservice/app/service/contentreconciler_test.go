@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentReconciler_Reconcile(t *testing.T) {
+	available := newSortedUUIDs(3)
+	missing := uuid.New()
+	private := uuid.New()
+
+	contentIDs := append(append([]uuid.UUID{}, available...), missing, private)
+	sortUUIDs(contentIDs)
+
+	checker := &mockContentChecker{missing: []uuid.UUID{missing}, unavailable: []uuid.UUID{private}}
+	tracker := &mockReconciliationTracker{}
+	playlistService := &mockContentPlaylistService{}
+	reconciler := NewContentReconciler(checker, mockContentCache{}, &mockPlaylistContentStorage{contentIDs: contentIDs}, tracker, playlistService, 2)
+
+	report, err := reconciler.Reconcile()
+	assert.NoError(t, err)
+	assert.Equal(t, ContentReconciliationReport{
+		CheckedContent:     5,
+		AvailableContent:   3,
+		UnavailableContent: 1,
+		MissingContent:     1,
+		Completed:          true,
+	}, report)
+	assert.ElementsMatch(t, []uuid.UUID{missing}, playlistService.removed)
+	assert.ElementsMatch(t, []uuid.UUID{private}, playlistService.unavailable)
+	assert.ElementsMatch(t, available, playlistService.available)
+	assert.Nil(t, tracker.cursor)
+	assert.False(t, tracker.locked)
+
+	// run interrupted by content service outage resumes from saved cursor
+	tracker = &mockReconciliationTracker{}
+	playlistService = &mockContentPlaylistService{}
+	checker = &mockContentChecker{failAfter: 1}
+	reconciler = NewContentReconciler(checker, mockContentCache{}, &mockPlaylistContentStorage{contentIDs: contentIDs}, tracker, playlistService, 2)
+
+	report, err = reconciler.Reconcile()
+	assert.Equal(t, ErrContentServiceUnavailable, errors.Cause(err))
+	assert.Equal(t, 2, report.CheckedContent)
+	assert.False(t, report.Completed)
+	assert.Equal(t, contentIDs[1], *tracker.cursor)
+
+	checker.failAfter = 0
+	report, err = reconciler.Reconcile()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.CheckedContent)
+	assert.True(t, report.Completed)
+	assert.ElementsMatch(t, contentIDs, playlistService.available)
+}
+
+type mockContentChecker struct {
+	missing     []uuid.UUID
+	unavailable []uuid.UUID
+	// failAfter makes checker unavailable after given count of calls when set
+	failAfter int
+	calls     int
+}
+
+func (checker *mockContentChecker) ContentExists(contentIDs []uuid.UUID) error {
+	checker.calls++
+	if checker.failAfter != 0 && checker.calls > checker.failAfter {
+		return errors.WithStack(ErrContentServiceUnavailable)
+	}
+
+	err := &ContentNotFoundError{
+		MissingContentIDs:     intersectUUIDs(contentIDs, checker.missing),
+		UnavailableContentIDs: intersectUUIDs(contentIDs, checker.unavailable),
+	}
+	if len(err.MissingContentIDs) == 0 && len(err.UnavailableContentIDs) == 0 {
+		return nil
+	}
+	return err
+}
+
+type mockContentCache struct{}
+
+func (cache mockContentCache) Invalidate([]uuid.UUID) {}
+
+type mockPlaylistContentStorage struct {
+	contentIDs []uuid.UUID
+}
+
+func (storage *mockPlaylistContentStorage) PendingContentIDs(int) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (storage *mockPlaylistContentStorage) ContentIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var result []uuid.UUID
+	for _, id := range storage.contentIDs {
+		if after != nil && bytes.Compare(id[:], after[:]) <= 0 {
+			continue
+		}
+		if len(result) == limit {
+			break
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+type mockReconciliationTracker struct {
+	locked bool
+	cursor *uuid.UUID
+}
+
+func (tracker *mockReconciliationTracker) Lock() error {
+	tracker.locked = true
+	return nil
+}
+
+func (tracker *mockReconciliationTracker) Unlock() error {
+	tracker.locked = false
+	return nil
+}
+
+func (tracker *mockReconciliationTracker) Cursor() (*uuid.UUID, error) {
+	return tracker.cursor, nil
+}
+
+func (tracker *mockReconciliationTracker) SaveCursor(cursor *uuid.UUID) error {
+	tracker.cursor = cursor
+	return nil
+}
+
+// mockContentPlaylistService records content-driven changes only
+type mockContentPlaylistService struct {
+	PlaylistService
+	removed     []uuid.UUID
+	available   []uuid.UUID
+	unavailable []uuid.UUID
+}
+
+func (service *mockContentPlaylistService) RemoveFromPlaylists(contentIDs []uuid.UUID) error {
+	service.removed = append(service.removed, contentIDs...)
+	return nil
+}
+
+func (service *mockContentPlaylistService) SetContentAvailability(contentIDs []uuid.UUID, available bool) error {
+	if available {
+		service.available = append(service.available, contentIDs...)
+	} else {
+		service.unavailable = append(service.unavailable, contentIDs...)
+	}
+	return nil
+}
+
+func intersectUUIDs(ids []uuid.UUID, other []uuid.UUID) []uuid.UUID {
+	return excludeUUIDs(ids, excludeUUIDs(ids, other))
+}
+
+func newSortedUUIDs(count int) []uuid.UUID {
+	result := make([]uuid.UUID, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, uuid.New())
+	}
+	sortUUIDs(result)
+	return result
+}
+
+func sortUUIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+const dataExportLockName = "playlist-service-data-export-lock"
+
+var (
+	ErrDataExportNotFound     = errors.New("data export not found")
+	ErrDataExportNotCompleted = errors.New("data export is not completed yet")
+)
+
+type DataExportStatus int
+
+const (
+	DataExportPending DataExportStatus = iota
+	DataExportCompleted
+)
+
+type DataExport struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	IncludeCSV  bool
+	Status      DataExportStatus
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// DataExportRepository keeps archives apart from exports to not load them on status checks
+type DataExportRepository interface {
+	Store(export DataExport) error
+	Find(id uuid.UUID) (DataExport, error)
+	FindPending(limit int) ([]DataExport, error)
+	StoreArchive(id uuid.UUID, archive []byte) error
+	FindArchive(id uuid.UUID) ([]byte, error)
+}
+
+// DataExportCompletedEvent announces that user can download archive
+type DataExportCompletedEvent struct {
+	ExportID uuid.UUID
+	UserID   uuid.UUID
+}
+
+func (e DataExportCompletedEvent) ID() string {
+	return "data_export_completed"
+}
+
+type DataExportService interface {
+	// RequestDataExport schedules export of all user data, archive is built asynchronously
+	RequestDataExport(userDescriptor auth.UserDescriptor, includeCSV bool) (uuid.UUID, error)
+	// GetDataExport returns export requested by user, exports of other users are not found
+	GetDataExport(id uuid.UUID, userDescriptor auth.UserDescriptor) (DataExport, error)
+	GetDataExportArchive(id uuid.UUID, userDescriptor auth.UserDescriptor) ([]byte, error)
+	// ProcessPendingExports builds archives for pending exports, returns count of completed exports
+	ProcessPendingExports() (int, error)
+}
+
+func NewDataExportService(
+	unitOfWorkFactory UnitOfWorkFactory,
+	playlistQueryService query.PlaylistQueryService,
+	storedEventQueryService query.StoredEventQueryService,
+	eventDispatcher domain.EventDispatcher,
+	batchSize int,
+) DataExportService {
+	return &dataExportService{
+		unitOfWorkFactory:       unitOfWorkFactory,
+		playlistQueryService:    playlistQueryService,
+		storedEventQueryService: storedEventQueryService,
+		eventDispatcher:         eventDispatcher,
+		batchSize:               batchSize,
+	}
+}
+
+type dataExportService struct {
+	unitOfWorkFactory       UnitOfWorkFactory
+	playlistQueryService    query.PlaylistQueryService
+	storedEventQueryService query.StoredEventQueryService
+	eventDispatcher         domain.EventDispatcher
+	batchSize               int
+}
+
+func (service *dataExportService) RequestDataExport(userDescriptor auth.UserDescriptor, includeCSV bool) (id uuid.UUID, err error) {
+	unitOfWork, err := service.unitOfWorkFactory.NewUnitOfWork("")
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	defer func() {
+		err = unitOfWork.Complete(err)
+	}()
+
+	export := DataExport{
+		ID:         uuid.New(),
+		UserID:     userDescriptor.UserID,
+		IncludeCSV: includeCSV,
+		Status:     DataExportPending,
+		CreatedAt:  time.Now(),
+	}
+
+	return export.ID, unitOfWork.DataExportRepository().Store(export)
+}
+
+func (service *dataExportService) GetDataExport(id uuid.UUID, userDescriptor auth.UserDescriptor) (export DataExport, err error) {
+	unitOfWork, err := service.unitOfWorkFactory.NewUnitOfWork("")
+	if err != nil {
+		return DataExport{}, err
+	}
+	defer func() {
+		err = unitOfWork.Complete(err)
+	}()
+
+	return findUserDataExport(unitOfWork.DataExportRepository(), id, userDescriptor.UserID)
+}
+
+func (service *dataExportService) GetDataExportArchive(id uuid.UUID, userDescriptor auth.UserDescriptor) (archive []byte, err error) {
+	unitOfWork, err := service.unitOfWorkFactory.NewUnitOfWork("")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = unitOfWork.Complete(err)
+	}()
+
+	repo := unitOfWork.DataExportRepository()
+
+	export, err := findUserDataExport(repo, id, userDescriptor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if export.Status != DataExportCompleted {
+		return nil, ErrDataExportNotCompleted
+	}
+
+	return repo.FindArchive(id)
+}
+
+func (service *dataExportService) ProcessPendingExports() (int, error) {
+	exports, err := service.findPendingExports()
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, export := range exports {
+		processed, err := service.processExport(export.ID)
+		if err != nil {
+			return completed, err
+		}
+		if processed {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+func (service *dataExportService) findPendingExports() (exports []DataExport, err error) {
+	unitOfWork, err := service.unitOfWorkFactory.NewUnitOfWork("")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = unitOfWork.Complete(err)
+	}()
+
+	return unitOfWork.DataExportRepository().FindPending(service.batchSize)
+}
+
+// processExport holds export lock so export is built once when several instances found it pending
+func (service *dataExportService) processExport(id uuid.UUID) (processed bool, err error) {
+	unitOfWork, err := service.unitOfWorkFactory.NewUnitOfWork(dataExportLockName + id.String())
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		err = unitOfWork.Complete(err)
+	}()
+
+	repo := unitOfWork.DataExportRepository()
+
+	export, err := repo.Find(id)
+	if err != nil {
+		return false, err
+	}
+	if export.Status != DataExportPending {
+		return false, nil
+	}
+
+	playlists, err := service.playlistQueryService.GetPlaylists(query.PlaylistSpecification{
+		OwnerIDs: []uuid.UUID{export.UserID},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	events, err := service.storedEventQueryService.GetUserEvents(export.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	completedAt := time.Now()
+
+	archive, err := buildDataExportArchive(export, playlists, events, completedAt)
+	if err != nil {
+		return false, err
+	}
+
+	err = repo.StoreArchive(export.ID, archive)
+	if err != nil {
+		return false, err
+	}
+
+	export.Status = DataExportCompleted
+	export.CompletedAt = &completedAt
+
+	err = repo.Store(export)
+	if err != nil {
+		return false, err
+	}
+
+	return true, service.eventDispatcher.Dispatch(DataExportCompletedEvent{
+		ExportID: export.ID,
+		UserID:   export.UserID,
+	})
+}
+
+func findUserDataExport(repo DataExportRepository, id uuid.UUID, userID uuid.UUID) (DataExport, error) {
+	export, err := repo.Find(id)
+	if err != nil {
+		return DataExport{}, err
+	}
+	if export.UserID != userID {
+		return DataExport{}, ErrDataExportNotFound
+	}
+	return export, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/app/query"
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+func TestDataExportService(t *testing.T) {
+	user := auth.UserDescriptor{UserID: uuid.New()}
+	otherUser := auth.UserDescriptor{UserID: uuid.New()}
+	playlistID := uuid.New()
+	itemCreatedAt := time.Now()
+
+	repo := &mockDataExportRepository{exports: map[uuid.UUID]DataExport{}, archives: map[uuid.UUID][]byte{}}
+	dispatcher := &mockEventDispatcher{}
+	exportService := NewDataExportService(
+		&mockUnitOfWorkFactory{repo: repo},
+		&mockPlaylistQueryService{playlists: []query.PlaylistView{{
+			ID:      playlistID,
+			Name:    "favourites",
+			OwnerID: user.UserID,
+			PlaylistItems: []query.PlaylistItemView{
+				{ID: uuid.New(), ContentID: uuid.New(), CreatedAt: &itemCreatedAt},
+			},
+		}}},
+		&mockStoredEventQueryService{events: []query.StoredEventView{
+			{ID: uuid.New(), Type: "playlist_created", Body: `{"Type":"playlist_created"}`},
+		}},
+		dispatcher,
+		10,
+	)
+
+	exportID, err := exportService.RequestDataExport(user, true)
+	assert.NoError(t, err)
+
+	{
+		export, err := exportService.GetDataExport(exportID, user)
+		assert.NoError(t, err)
+		assert.Equal(t, DataExportPending, export.Status)
+
+		_, err = exportService.GetDataExportArchive(exportID, user)
+		assert.Equal(t, ErrDataExportNotCompleted, errors.Cause(err))
+	}
+
+	{
+		completed, err := exportService.ProcessPendingExports()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, completed)
+		assert.Equal(t, []domain.Event{DataExportCompletedEvent{ExportID: exportID, UserID: user.UserID}}, dispatcher.events)
+
+		completed, err = exportService.ProcessPendingExports()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, completed, "completed exports are not processed again")
+	}
+
+	{
+		_, err := exportService.GetDataExport(exportID, otherUser)
+		assert.Equal(t, ErrDataExportNotFound, errors.Cause(err), "exports of other users are hidden")
+
+		_, err = exportService.GetDataExportArchive(exportID, otherUser)
+		assert.Equal(t, ErrDataExportNotFound, errors.Cause(err))
+	}
+
+	{
+		export, err := exportService.GetDataExport(exportID, user)
+		assert.NoError(t, err)
+		assert.Equal(t, DataExportCompleted, export.Status)
+		assert.NotNil(t, export.CompletedAt)
+
+		archive, err := exportService.GetDataExportArchive(exportID, user)
+		assert.NoError(t, err)
+
+		files := readZipArchive(t, archive)
+		assert.Len(t, files, 3)
+		assert.Contains(t, files, dataExportPlaylistsCSVFileName)
+		assert.Contains(t, files, dataExportItemsCSVFileName)
+
+		var data exportedData
+		assert.NoError(t, json.Unmarshal(files[dataExportJSONFileName], &data))
+		assert.Equal(t, user.UserID, data.UserID)
+		assert.Len(t, data.Playlists, 1)
+		assert.Equal(t, playlistID, data.Playlists[0].ID)
+		assert.Len(t, data.Playlists[0].Items, 1)
+		assert.Len(t, data.Events, 1)
+		assert.Equal(t, "playlist_created", data.Events[0].Type)
+	}
+}
+
+func readZipArchive(t *testing.T, archive []byte) map[string][]byte {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		files[file.Name] = content
+	}
+	return files
+}
+
+type mockUnitOfWorkFactory struct {
+	repo DataExportRepository
+}
+
+func (factory *mockUnitOfWorkFactory) NewUnitOfWork(string) (UnitOfWork, error) {
+	return &mockUnitOfWork{repo: factory.repo}, nil
+}
+
+type mockUnitOfWork struct {
+	RepositoryProvider
+	repo DataExportRepository
+}
+
+func (unitOfWork *mockUnitOfWork) DataExportRepository() DataExportRepository {
+	return unitOfWork.repo
+}
+
+func (unitOfWork *mockUnitOfWork) Complete(err error) error {
+	return err
+}
+
+type mockDataExportRepository struct {
+	exports  map[uuid.UUID]DataExport
+	archives map[uuid.UUID][]byte
+}
+
+func (repo *mockDataExportRepository) Store(export DataExport) error {
+	repo.exports[export.ID] = export
+	return nil
+}
+
+func (repo *mockDataExportRepository) Find(id uuid.UUID) (DataExport, error) {
+	export, ok := repo.exports[id]
+	if !ok {
+		return DataExport{}, ErrDataExportNotFound
+	}
+	return export, nil
+}
+
+func (repo *mockDataExportRepository) FindPending(limit int) ([]DataExport, error) {
+	var result []DataExport
+	for _, export := range repo.exports {
+		if export.Status == DataExportPending && len(result) < limit {
+			result = append(result, export)
+		}
+	}
+	return result, nil
+}
+
+func (repo *mockDataExportRepository) StoreArchive(id uuid.UUID, archive []byte) error {
+	repo.archives[id] = archive
+	return nil
+}
+
+func (repo *mockDataExportRepository) FindArchive(id uuid.UUID) ([]byte, error) {
+	archive, ok := repo.archives[id]
+	if !ok {
+		return nil, ErrDataExportNotFound
+	}
+	return archive, nil
+}
+
+type mockPlaylistQueryService struct {
+	query.PlaylistQueryService
+	playlists []query.PlaylistView
+}
+
+func (service *mockPlaylistQueryService) GetPlaylists(query.PlaylistSpecification) ([]query.PlaylistView, error) {
+	return service.playlists, nil
+}
+
+type mockStoredEventQueryService struct {
+	events []query.StoredEventView
+}
+
+func (service *mockStoredEventQueryService) GetUserEvents(uuid.UUID) ([]query.StoredEventView, error) {
+	return service.events, nil
+}
+
+type mockEventDispatcher struct {
+	events []domain.Event
+}
+
+func (dispatcher *mockEventDispatcher) Dispatch(event domain.Event) error {
+	dispatcher.events = append(dispatcher.events, event)
+	return nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+const (
+	dataExportJSONFileName         = "data.json"
+	dataExportPlaylistsCSVFileName = "playlists.csv"
+	dataExportItemsCSVFileName     = "playlist_items.csv"
+	dataExportCSVTimeLayout        = time.RFC3339
+)
+
+type exportedData struct {
+	UserID     uuid.UUID          `json:"user_id"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Playlists  []exportedPlaylist `json:"playlists"`
+	Events     []exportedEvent    `json:"events"`
+}
+
+type exportedPlaylist struct {
+	ID        uuid.UUID              `json:"playlist_id"`
+	Name      string                 `json:"name"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Items     []exportedPlaylistItem `json:"items"`
+}
+
+type exportedPlaylistItem struct {
+	ID                  uuid.UUID  `json:"playlist_item_id"`
+	ContentID           uuid.UUID  `json:"content_id"`
+	PendingVerification bool       `json:"pending_verification"`
+	Unavailable         bool       `json:"unavailable"`
+	CreatedAt           *time.Time `json:"created_at"`
+}
+
+type exportedEvent struct {
+	Type      string          `json:"type"`
+	Body      json.RawMessage `json:"body"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// buildDataExportArchive packs user data into zip with data.json and optional csv tables of playlists and items
+func buildDataExportArchive(
+	export DataExport,
+	playlists []query.PlaylistView,
+	events []query.StoredEventView,
+	exportedAt time.Time,
+) ([]byte, error) {
+	data := exportedData{
+		UserID:     export.UserID,
+		ExportedAt: exportedAt,
+		Playlists:  make([]exportedPlaylist, 0, len(playlists)),
+		Events:     make([]exportedEvent, 0, len(events)),
+	}
+
+	for _, playlist := range playlists {
+		items := make([]exportedPlaylistItem, 0, len(playlist.PlaylistItems))
+		for _, item := range playlist.PlaylistItems {
+			items = append(items, exportedPlaylistItem{
+				ID:                  item.ID,
+				ContentID:           item.ContentID,
+				PendingVerification: item.PendingVerification,
+				Unavailable:         item.Unavailable,
+				CreatedAt:           item.CreatedAt,
+			})
+		}
+
+		data.Playlists = append(data.Playlists, exportedPlaylist{
+			ID:        playlist.ID,
+			Name:      playlist.Name,
+			CreatedAt: playlist.CreatedAt,
+			UpdatedAt: playlist.UpdatedAt,
+			Items:     items,
+		})
+	}
+
+	for _, event := range events {
+		data.Events = append(data.Events, exportedEvent{
+			Type:      event.Type,
+			Body:      json.RawMessage(event.Body),
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := zip.NewWriter(buffer)
+
+	err := writeJSONFile(writer, dataExportJSONFileName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.IncludeCSV {
+		err = writeCSVFile(writer, dataExportPlaylistsCSVFileName, playlistsCSVRecords(data.Playlists))
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeCSVFile(writer, dataExportItemsCSVFileName, playlistItemsCSVRecords(data.Playlists))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func playlistsCSVRecords(playlists []exportedPlaylist) [][]string {
+	records := [][]string{{"playlist_id", "name", "created_at", "updated_at"}}
+	for _, playlist := range playlists {
+		records = append(records, []string{
+			playlist.ID.String(),
+			playlist.Name,
+			playlist.CreatedAt.Format(dataExportCSVTimeLayout),
+			playlist.UpdatedAt.Format(dataExportCSVTimeLayout),
+		})
+	}
+	return records
+}
+
+func playlistItemsCSVRecords(playlists []exportedPlaylist) [][]string {
+	records := [][]string{{"playlist_id", "playlist_item_id", "content_id", "pending_verification", "unavailable", "created_at"}}
+	for _, playlist := range playlists {
+		for _, item := range playlist.Items {
+			var createdAt string
+			if item.CreatedAt != nil {
+				createdAt = item.CreatedAt.Format(dataExportCSVTimeLayout)
+			}
+
+			records = append(records, []string{
+				playlist.ID.String(),
+				item.ID.String(),
+				item.ContentID.String(),
+				strconv.FormatBool(item.PendingVerification),
+				strconv.FormatBool(item.Unavailable),
+				createdAt,
+			})
+		}
+	}
+	return records
+}
+
+func writeJSONFile(writer *zip.Writer, name string, data interface{}) error {
+	file, err := writer.Create(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return errors.WithStack(encoder.Encode(data))
+}
+
+func writeCSVFile(writer *zip.Writer, name string, records [][]string) error {
+	file, err := writer.Create(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(csv.NewWriter(file).WriteAll(records))
+}
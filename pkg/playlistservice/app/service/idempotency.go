@@ -0,0 +1,182 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	requestPartsSeparator   = "\x00"
+)
+
+var (
+	ErrIdempotencyKeyReused      = errors.New("idempotency key reused with different request")
+	ErrIdempotencyKeyTooLong     = errors.New("idempotency key too long")
+	ErrIdempotencyRecordNotFound = errors.New("idempotency record not found")
+)
+
+type IdempotencyRecord struct {
+	Key         string
+	UserID      uuid.UUID
+	RequestHash string
+	Response    string
+	CreatedAt   time.Time
+}
+
+type IdempotencyRecordRepository interface {
+	Find(key string, userID uuid.UUID) (IdempotencyRecord, error)
+	Store(record IdempotencyRecord) error
+	// RemoveCreatedBefore removes up to limit records created before given time and returns count of removed records
+	RemoveCreatedBefore(before time.Time, limit int) (int, error)
+}
+
+// IdempotentResultFinder finds result stored for retried command
+type IdempotentResultFinder interface {
+	// FindResult returns result stored for idempotency key of command within TTL, ok is false when command was not run,
+	// ErrIdempotencyKeyReused is returned when key was used by other request
+	FindResult(command Command, key string) (result CommandResult, ok bool, err error)
+}
+
+func NewIdempotentResultFinder(repo IdempotencyRecordRepository, idempotencyKeyTTL time.Duration) IdempotentResultFinder {
+	return &idempotentResultFinder{
+		repo:              repo,
+		idempotencyKeyTTL: idempotencyKeyTTL,
+	}
+}
+
+type idempotentResultFinder struct {
+	repo              IdempotencyRecordRepository
+	idempotencyKeyTTL time.Duration
+}
+
+func (finder *idempotentResultFinder) FindResult(command Command, key string) (CommandResult, bool, error) {
+	record, err := finder.repo.Find(key, command.Actor().UserID)
+	if err == ErrIdempotencyRecordNotFound {
+		return CommandResult{}, false, nil
+	}
+	if err != nil {
+		return CommandResult{}, false, err
+	}
+
+	if time.Since(record.CreatedAt) >= finder.idempotencyKeyTTL {
+		return CommandResult{}, false, nil
+	}
+
+	if record.RequestHash != hashCommand(command) {
+		return CommandResult{}, false, ErrIdempotencyKeyReused
+	}
+
+	result, err := deserializeCommandResult(record.Response)
+	return result, err == nil, err
+}
+
+// IdempotencyRecordPurger removes records of expired idempotency keys, expired records are ignored by lookup anyway
+type IdempotencyRecordPurger interface {
+	// Purge removes expired records in batches and returns count of removed records
+	Purge() (int, error)
+}
+
+func NewIdempotencyRecordPurger(repo IdempotencyRecordRepository, idempotencyKeyTTL time.Duration, batchSize int) IdempotencyRecordPurger {
+	return &idempotencyRecordPurger{
+		repo:              repo,
+		idempotencyKeyTTL: idempotencyKeyTTL,
+		batchSize:         batchSize,
+	}
+}
+
+type idempotencyRecordPurger struct {
+	repo              IdempotencyRecordRepository
+	idempotencyKeyTTL time.Duration
+	batchSize         int
+}
+
+func (purger *idempotencyRecordPurger) Purge() (int, error) {
+	before := time.Now().Add(-purger.idempotencyKeyTTL)
+
+	purged := 0
+	for {
+		removed, err := purger.repo.RemoveCreatedBefore(before, purger.batchSize)
+		purged += removed
+		if err != nil || removed < purger.batchSize {
+			return purged, err
+		}
+	}
+}
+
+// NewIdempotencyMiddleware runs command at most once per idempotency key and caller within TTL,
+// retried requests get stored result, must be placed after unit of work middleware
+func NewIdempotencyMiddleware(idempotencyKeyTTL time.Duration) CommandMiddleware {
+	return func(next CommandHandlerFunc) CommandHandlerFunc {
+		return func(ctx *CommandContext) (CommandResult, error) {
+			key := ctx.Metadata.IdempotencyKey
+			if key == "" {
+				return next(ctx)
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				return CommandResult{}, ErrIdempotencyKeyTooLong
+			}
+
+			repo := ctx.Provider.IdempotencyRecordRepository()
+
+			result, ok, err := NewIdempotentResultFinder(repo, idempotencyKeyTTL).FindResult(ctx.Command, key)
+			if err != nil || ok {
+				return result, err
+			}
+
+			result, err = next(ctx)
+			if err != nil {
+				return CommandResult{}, err
+			}
+
+			response, err := serializeCommandResult(result)
+			if err != nil {
+				return CommandResult{}, err
+			}
+
+			return result, repo.Store(IdempotencyRecord{
+				Key:         key,
+				UserID:      ctx.Command.Actor().UserID,
+				RequestHash: hashCommand(ctx.Command),
+				Response:    response,
+				CreatedAt:   time.Now(),
+			})
+		}
+	}
+}
+
+func hashCommand(command Command) string {
+	return hashRequest(append([]string{command.CommandName()}, command.Payload()...)...)
+}
+
+func hashRequest(requestParts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(requestParts, requestPartsSeparator)))
+	return hex.EncodeToString(hash[:])
+}
+
+// serializeCommandResult stores created ID as json string and null when command creates nothing
+func serializeCommandResult(result CommandResult) (string, error) {
+	var response interface{}
+	if result.ID != uuid.Nil {
+		response = result.ID
+	}
+
+	data, err := json.Marshal(response)
+	return string(data), err
+}
+
+func deserializeCommandResult(response string) (CommandResult, error) {
+	var id *uuid.UUID
+	err := json.Unmarshal([]byte(response), &id)
+	if err != nil || id == nil {
+		return CommandResult{}, err
+	}
+	return CommandResult{ID: *id}, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	repo := &mockIdempotencyRecordRepository{records: map[string]IdempotencyRecord{}}
+	provider := &mockIdempotencyRecordRepositoryProvider{repo: repo}
+
+	calls := 0
+	handler := NewIdempotencyMiddleware(time.Hour)(func(*CommandContext) (CommandResult, error) {
+		calls++
+		return CommandResult{ID: uuid.New()}, nil
+	})
+
+	dispatch := func(command Command, key string) (CommandResult, error) {
+		return handler(&CommandContext{
+			Command:  command,
+			Metadata: CommandMetadata{IdempotencyKey: key},
+			Provider: provider,
+		})
+	}
+
+	user := auth.UserDescriptor{UserID: uuid.New()}
+	create := CreatePlaylistCommand{Name: "magic", UserDescriptor: user}
+
+	first, err := dispatch(create, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	{
+		replayed, err := dispatch(create, "key")
+		assert.NoError(t, err)
+		assert.Equal(t, first, replayed, "retried request gets stored result")
+		assert.Equal(t, 1, calls, "retried request is not handled again")
+	}
+
+	{
+		_, err := dispatch(CreatePlaylistCommand{Name: "other magic", UserDescriptor: user}, "key")
+		assert.Equal(t, ErrIdempotencyKeyReused, err)
+		assert.Equal(t, 1, calls)
+	}
+
+	{
+		_, err := dispatch(CreatePlaylistCommand{Name: "other magic", UserDescriptor: auth.UserDescriptor{UserID: uuid.New()}}, "key")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls, "keys of other users do not conflict")
+	}
+
+	{
+		record := repo.records["key"+user.UserID.String()]
+		record.CreatedAt = time.Now().Add(-2 * time.Hour)
+		repo.records["key"+user.UserID.String()] = record
+
+		replayed, err := dispatch(create, "key")
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, replayed, "expired key runs command again")
+		assert.Equal(t, 3, calls)
+	}
+
+	{
+		_, err := dispatch(create, "")
+		assert.NoError(t, err)
+		_, err = dispatch(create, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 5, calls, "request without key is not deduplicated")
+	}
+
+	{
+		_, err := dispatch(create, strings.Repeat("k", maxIdempotencyKeyLength+1))
+		assert.Equal(t, ErrIdempotencyKeyTooLong, err)
+		assert.Equal(t, 5, calls)
+	}
+}
+
+func TestPlaylistService_AddToPlaylistReplay(t *testing.T) {
+	user := auth.UserDescriptor{UserID: uuid.New()}
+	playlistID, contentID, playlistItemID := uuid.New(), uuid.New(), uuid.New()
+
+	repo := &mockIdempotencyRecordRepository{records: map[string]IdempotencyRecord{}}
+	response, err := serializeCommandResult(CommandResult{ID: playlistItemID})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(IdempotencyRecord{
+		Key:         "key",
+		UserID:      user.UserID,
+		RequestHash: hashCommand(AddToPlaylistCommand{PlaylistID: playlistID, ContentID: contentID, UserDescriptor: user}),
+		Response:    response,
+		CreatedAt:   time.Now(),
+	}))
+
+	// content became unavailable after first attempt succeeded
+	checker := &mockContentChecker{unavailable: []uuid.UUID{contentID}}
+	playlistService := NewPlaylistService(
+		checker,
+		nil,
+		nil,
+		ContentCheckFallbackReject,
+		NewIdempotentResultFinder(repo, time.Hour),
+	)
+
+	{
+		id, err := playlistService.AddToPlaylist(playlistID, user, contentID, CommandMetadata{IdempotencyKey: "key"})
+		assert.NoError(t, err)
+		assert.Equal(t, playlistItemID, id)
+		assert.Equal(t, 0, checker.calls, "content is not checked for retried request")
+	}
+
+	{
+		_, err := playlistService.AddToPlaylist(uuid.New(), user, contentID, CommandMetadata{IdempotencyKey: "key"})
+		assert.Equal(t, ErrIdempotencyKeyReused, err)
+		assert.Equal(t, 0, checker.calls)
+	}
+}
+
+func TestIdempotencyRecordPurger(t *testing.T) {
+	repo := &mockIdempotencyRecordRepository{records: map[string]IdempotencyRecord{}}
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, repo.Store(IdempotencyRecord{Key: "expired", UserID: uuid.New(), CreatedAt: time.Now().Add(-2 * time.Hour)}))
+	}
+	assert.NoError(t, repo.Store(IdempotencyRecord{Key: "key", UserID: uuid.New(), CreatedAt: time.Now()}))
+
+	purged, err := NewIdempotencyRecordPurger(repo, time.Hour, 2).Purge()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, purged, "expired records are removed in batches")
+	assert.Len(t, repo.records, 1, "records within TTL are kept")
+}
+
+type mockIdempotencyRecordRepositoryProvider struct {
+	RepositoryProvider
+	repo IdempotencyRecordRepository
+}
+
+func (provider *mockIdempotencyRecordRepositoryProvider) IdempotencyRecordRepository() IdempotencyRecordRepository {
+	return provider.repo
+}
+
+type mockIdempotencyRecordRepository struct {
+	records map[string]IdempotencyRecord
+}
+
+func (repo *mockIdempotencyRecordRepository) Find(key string, userID uuid.UUID) (IdempotencyRecord, error) {
+	record, ok := repo.records[key+userID.String()]
+	if !ok {
+		return IdempotencyRecord{}, ErrIdempotencyRecordNotFound
+	}
+	return record, nil
+}
+
+func (repo *mockIdempotencyRecordRepository) Store(record IdempotencyRecord) error {
+	repo.records[record.Key+record.UserID.String()] = record
+	return nil
+}
+
+func (repo *mockIdempotencyRecordRepository) RemoveCreatedBefore(before time.Time, limit int) (int, error) {
+	removed := 0
+	for id, record := range repo.records {
+		if removed == limit {
+			break
+		}
+		if record.CreatedAt.Before(before) {
+			delete(repo.records, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
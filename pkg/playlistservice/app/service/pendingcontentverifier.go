@@ -0,0 +1,95 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// PlaylistContentStorage lists content referenced by playlist items
+type PlaylistContentStorage interface {
+	// PendingContentIDs returns content of items accepted while content service was unavailable
+	PendingContentIDs(limit int) ([]uuid.UUID, error)
+	// ContentIDs returns distinct content greater than after in ascending order
+	ContentIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error)
+}
+
+// PendingContentVerificationLock makes verification runs exclusive across service instances
+type PendingContentVerificationLock interface {
+	Lock() error
+	Unlock() error
+}
+
+type PendingContentVerifier interface {
+	// VerifyPendingContent makes pending items with public content available, items with private content unavailable
+	// and removes items with missing content
+	VerifyPendingContent() error
+}
+
+func NewPendingContentVerifier(
+	checker ContentChecker,
+	storage PlaylistContentStorage,
+	lock PendingContentVerificationLock,
+	playlistService PlaylistService,
+	batchSize int,
+) PendingContentVerifier {
+	return &pendingContentVerifier{
+		checker:         checker,
+		storage:         storage,
+		lock:            lock,
+		playlistService: playlistService,
+		batchSize:       batchSize,
+	}
+}
+
+type pendingContentVerifier struct {
+	checker         ContentChecker
+	storage         PlaylistContentStorage
+	lock            PendingContentVerificationLock
+	playlistService PlaylistService
+	batchSize       int
+}
+
+func (verifier *pendingContentVerifier) VerifyPendingContent() (err error) {
+	err = verifier.lock.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlockErr := verifier.lock.Unlock()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	for {
+		contentIDs, err := verifier.storage.PendingContentIDs(verifier.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(contentIDs) == 0 {
+			return nil
+		}
+
+		err = verifier.verify(contentIDs)
+		if err != nil {
+			if errors.Cause(err) == ErrContentServiceUnavailable {
+				// items stay pending until next run
+				return nil
+			}
+			return err
+		}
+
+		if len(contentIDs) < verifier.batchSize {
+			return nil
+		}
+	}
+}
+
+func (verifier *pendingContentVerifier) verify(contentIDs []uuid.UUID) error {
+	result, err := checkContent(verifier.checker, contentIDs)
+	if err != nil {
+		return err
+	}
+
+	return applyContentCheck(verifier.playlistService, result)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// PlaylistProjectorName is name of projector position in tracked stored events
+const PlaylistProjectorName = "playlist_read_model_projector"
+
+// PlaylistReadModelStorage keeps denormalized read model of playlists in sync with write model
+type PlaylistReadModelStorage interface {
+	// Project replaces read model of playlists with their committed state, playlists absent in write model are removed from read model
+	Project(playlistIDs []uuid.UUID) error
+	// PlaylistIDs returns ids of write model playlists ordered by id
+	PlaylistIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error)
+	// UnprojectedPlaylistIDs returns ids of write model playlists absent in read model ordered by id
+	UnprojectedPlaylistIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error)
+	// RemoveAbsentPlaylists removes read model of playlists absent in write model and returns count of removed
+	RemoveAbsentPlaylists() (int, error)
+}
+
+// PlaylistProjector is stored event transport that projects playlists mentioned by events to read model,
+// projection copies current state of playlist so redelivered and reordered events are harmless
+type PlaylistProjector interface {
+	Name() string
+	Send(eventType string, msgBody string) error
+	// Rebuild projects all playlists and removes read model of absent ones, returns count of projected playlists
+	Rebuild() (int, error)
+	// ProjectUnprojected projects playlists absent in read model, e.g. ones created before read model,
+	// returns count of projected playlists
+	ProjectUnprojected() (int, error)
+}
+
+func NewPlaylistProjector(storage PlaylistReadModelStorage, batchSize int) PlaylistProjector {
+	return &playlistProjector{
+		storage:   storage,
+		batchSize: batchSize,
+	}
+}
+
+type playlistProjector struct {
+	storage   PlaylistReadModelStorage
+	batchSize int
+}
+
+type playlistEventBody struct {
+	Payload struct {
+		PlaylistID *uuid.UUID `json:"playlist_id"`
+	}
+}
+
+func (projector *playlistProjector) Name() string {
+	return PlaylistProjectorName
+}
+
+func (projector *playlistProjector) Send(_ string, msgBody string) error {
+	var body playlistEventBody
+	err := json.Unmarshal([]byte(msgBody), &body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// events not related to playlists, like data export ones, do not change read model
+	if body.Payload.PlaylistID == nil {
+		return nil
+	}
+
+	return projector.storage.Project([]uuid.UUID{*body.Payload.PlaylistID})
+}
+
+func (projector *playlistProjector) Rebuild() (int, error) {
+	projected, err := projector.projectBatches(projector.storage.PlaylistIDs)
+	if err != nil {
+		return projected, err
+	}
+
+	_, err = projector.storage.RemoveAbsentPlaylists()
+	return projected, err
+}
+
+func (projector *playlistProjector) ProjectUnprojected() (int, error) {
+	return projector.projectBatches(projector.storage.UnprojectedPlaylistIDs)
+}
+
+func (projector *playlistProjector) projectBatches(playlistIDsFunc func(after *uuid.UUID, limit int) ([]uuid.UUID, error)) (int, error) {
+	projected := 0
+	var after *uuid.UUID
+
+	for {
+		playlistIDs, err := playlistIDsFunc(after, projector.batchSize)
+		if err != nil {
+			return projected, err
+		}
+		if len(playlistIDs) == 0 {
+			return projected, nil
+		}
+
+		err = projector.storage.Project(playlistIDs)
+		if err != nil {
+			return projected, err
+		}
+
+		projected += len(playlistIDs)
+		after = &playlistIDs[len(playlistIDs)-1]
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaylistProjector_Send(t *testing.T) {
+	storage := &mockPlaylistReadModelStorage{}
+	projector := NewPlaylistProjector(storage, 2)
+	playlistID := uuid.New()
+
+	err := projector.Send("playlist_item_added", fmt.Sprintf(`{"Type":"playlist_item_added","Payload":{"playlist_id":"%s","content_id":"%s"}}`, playlistID, uuid.New()))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]uuid.UUID{{playlistID}}, storage.projected)
+
+	err = projector.Send("data_export_completed", fmt.Sprintf(`{"Type":"data_export_completed","Payload":{"export_id":"%s"}}`, uuid.New()))
+	assert.NoError(t, err)
+	assert.Len(t, storage.projected, 1)
+
+	assert.Error(t, projector.Send("playlist_created", `{"Type":`))
+}
+
+func TestPlaylistProjector_Rebuild(t *testing.T) {
+	playlistIDs := newSortedUUIDs(5)
+	storage := &mockPlaylistReadModelStorage{playlistIDs: playlistIDs}
+	projector := NewPlaylistProjector(storage, 2)
+
+	projected, err := projector.Rebuild()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, projected)
+	assert.Equal(t, [][]uuid.UUID{playlistIDs[:2], playlistIDs[2:4], playlistIDs[4:]}, storage.projected)
+	assert.True(t, storage.absentRemoved)
+}
+
+func TestPlaylistProjector_ProjectUnprojected(t *testing.T) {
+	playlistIDs := newSortedUUIDs(5)
+	storage := &mockPlaylistReadModelStorage{
+		playlistIDs:    playlistIDs,
+		unprojectedIDs: []uuid.UUID{playlistIDs[0], playlistIDs[2], playlistIDs[3]},
+	}
+	projector := NewPlaylistProjector(storage, 2)
+
+	projected, err := projector.ProjectUnprojected()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, projected)
+	assert.Equal(t, [][]uuid.UUID{{playlistIDs[0], playlistIDs[2]}, {playlistIDs[3]}}, storage.projected)
+	assert.False(t, storage.absentRemoved, "read model of other playlists is not touched")
+}
+
+type mockPlaylistReadModelStorage struct {
+	playlistIDs    []uuid.UUID
+	unprojectedIDs []uuid.UUID
+	projected      [][]uuid.UUID
+	absentRemoved  bool
+}
+
+func (storage *mockPlaylistReadModelStorage) Project(playlistIDs []uuid.UUID) error {
+	storage.projected = append(storage.projected, playlistIDs)
+	return nil
+}
+
+func (storage *mockPlaylistReadModelStorage) PlaylistIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	start := 0
+	if after != nil {
+		for i, id := range storage.playlistIDs {
+			if id == *after {
+				start = i + 1
+			}
+		}
+	}
+	end := start + limit
+	if end > len(storage.playlistIDs) {
+		end = len(storage.playlistIDs)
+	}
+	return storage.playlistIDs[start:end], nil
+}
+
+func (storage *mockPlaylistReadModelStorage) UnprojectedPlaylistIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var result []uuid.UUID
+	for _, id := range storage.unprojectedIDs {
+		if after == nil || bytes.Compare(id[:], after[:]) > 0 {
+			result = append(result, id)
+		}
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (storage *mockPlaylistReadModelStorage) RemoveAbsentPlaylists() (int, error) {
+	storage.absentRemoved = true
+	return 0, nil
+}
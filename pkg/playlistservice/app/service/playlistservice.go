@@ -3,127 +3,336 @@ package service
 import (
 	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 
 	"playlistservice/pkg/playlistservice/domain"
 )
 
 const (
-	playlistLockName = "playlist-service-lock"
+	playlistLockName    = "playlist-service-lock"
+	ownerLockName       = "playlist-service-owner-lock"
+	contentSyncLockName = "playlist-service-content-sync-lock"
+
+	// batchSize limits count of playlists changed in single unit of work by batch commands
+	batchSize = 100
 )
 
 type PlaylistService interface {
-	CreatePlaylist(name string, userDescriptor auth.UserDescriptor) (uuid.UUID, error)
-	SetPlaylistName(id uuid.UUID, userDescriptor auth.UserDescriptor, newName string) error
-	AddToPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, contentID uuid.UUID) (uuid.UUID, error)
-	RemoveFromPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor) error
-	RemovePlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor) error
+	CreatePlaylist(name string, userDescriptor auth.UserDescriptor, metadata CommandMetadata) (uuid.UUID, error)
+	SetPlaylistName(id uuid.UUID, userDescriptor auth.UserDescriptor, newName string, metadata CommandMetadata) error
+	// SetPlaylistDiscoverable opts playlist in or out of public discovery feeds like trending playlists
+	SetPlaylistDiscoverable(id uuid.UUID, userDescriptor auth.UserDescriptor, discoverable bool, metadata CommandMetadata) error
+	AddToPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, contentID uuid.UUID, metadata CommandMetadata) (uuid.UUID, error)
+	RemoveFromPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, metadata CommandMetadata) error
+	RemovePlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, metadata CommandMetadata) error
 
+	// RemoveUserPlaylists removes all playlists owned by user, repeated calls do nothing
+	RemoveUserPlaylists(userID uuid.UUID) error
+	// RemoveFromPlaylists removes items with given content from all playlists
 	RemoveFromPlaylists(contentIDs []uuid.UUID) error
+	// SetContentAvailability makes items with given content available or unavailable in all playlists
+	SetContentAvailability(contentIDs []uuid.UUID, available bool) error
 }
 
+// NewPlaylistService creates service which dispatches playlist commands through command bus with given middlewares,
+// one of middlewares must provide unit of work to command context
 func NewPlaylistService(
 	contentService ContentChecker,
-	unitOfWorkFactory UnitOfWorkFactory,
 	eventDispatcher domain.EventDispatcher,
-	remover PlaylistRemover,
+	authorizationPolicy domain.AuthorizationPolicy,
+	contentCheckFallback ContentCheckFallback,
+	idempotentResultFinder IdempotentResultFinder,
+	middlewares ...CommandMiddleware,
 ) PlaylistService {
-	return &playlistService{
-		contentService:    contentService,
-		unitOfWorkFactory: unitOfWorkFactory,
-		eventDispatcher:   eventDispatcher,
-		remover:           remover,
+	service := &playlistService{
+		contentService:         contentService,
+		eventDispatcher:        eventDispatcher,
+		authorizationPolicy:    authorizationPolicy,
+		contentCheckFallback:   contentCheckFallback,
+		idempotentResultFinder: idempotentResultFinder,
 	}
+
+	service.commandBus = NewCommandBus(map[string]CommandHandlerFunc{
+		createPlaylistCommandName:          service.handleCreatePlaylist,
+		setPlaylistNameCommandName:         service.handleSetPlaylistName,
+		setPlaylistDiscoverableCommandName: service.handleSetPlaylistDiscoverable,
+		addToPlaylistCommandName:           service.handleAddToPlaylist,
+		removeFromPlaylistCommandName:      service.handleRemoveFromPlaylist,
+		removePlaylistCommandName:          service.handleRemovePlaylist,
+
+		removeOwnerPlaylistsCommandName:   service.handleRemoveOwnerPlaylists,
+		removeContentCommandName:          service.handleRemoveContent,
+		setContentAvailabilityCommandName: service.handleSetContentAvailability,
+	}, middlewares...)
+
+	return service
 }
 
 type playlistService struct {
-	contentService    ContentChecker
-	unitOfWorkFactory UnitOfWorkFactory
-	eventDispatcher   domain.EventDispatcher
-	remover           PlaylistRemover
+	contentService         ContentChecker
+	eventDispatcher        domain.EventDispatcher
+	authorizationPolicy    domain.AuthorizationPolicy
+	contentCheckFallback   ContentCheckFallback
+	idempotentResultFinder IdempotentResultFinder
+	commandBus             CommandBus
 }
 
-func (service *playlistService) CreatePlaylist(name string, userDescriptor auth.UserDescriptor) (uuid.UUID, error) {
-	var playlistID domain.PlaylistID
-	err := service.executeInUnitOfWorkWithServiceLock(playlistLockName, func(provider RepositoryProvider) error {
-		domainService := service.domainPlaylistService(provider)
-
-		var err error
+func (service *playlistService) CreatePlaylist(name string, userDescriptor auth.UserDescriptor, metadata CommandMetadata) (uuid.UUID, error) {
+	result, err := service.commandBus.Dispatch(CreatePlaylistCommand{
+		Name:           name,
+		UserDescriptor: userDescriptor,
+	}, metadata)
 
-		playlistID, err = domainService.CreatePlaylist(name, domain.PlaylistOwnerID(userDescriptor.UserID))
+	return result.ID, err
+}
 
-		return err
-	})
+func (service *playlistService) SetPlaylistName(id uuid.UUID, userDescriptor auth.UserDescriptor, newName string, metadata CommandMetadata) error {
+	_, err := service.commandBus.Dispatch(SetPlaylistNameCommand{
+		PlaylistID:     id,
+		NewName:        newName,
+		UserDescriptor: userDescriptor,
+	}, metadata)
 
-	return uuid.UUID(playlistID), err
+	return err
 }
 
-func (service *playlistService) SetPlaylistName(id uuid.UUID, userDescriptor auth.UserDescriptor, newName string) error {
-	return service.executeInUnitOfWorkWithServiceLock(playlistLockName+id.String(), func(provider RepositoryProvider) error {
-		domainService := service.domainPlaylistService(provider)
+func (service *playlistService) SetPlaylistDiscoverable(
+	id uuid.UUID,
+	userDescriptor auth.UserDescriptor,
+	discoverable bool,
+	metadata CommandMetadata,
+) error {
+	_, err := service.commandBus.Dispatch(SetPlaylistDiscoverableCommand{
+		PlaylistID:     id,
+		Discoverable:   discoverable,
+		UserDescriptor: userDescriptor,
+	}, metadata)
 
-		return domainService.SetPlaylistName(domain.PlaylistID(id), domain.PlaylistOwnerID(userDescriptor.UserID), newName)
-	})
+	return err
 }
 
-func (service *playlistService) AddToPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, contentID uuid.UUID) (uuid.UUID, error) {
+func (service *playlistService) AddToPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, contentID uuid.UUID, metadata CommandMetadata) (uuid.UUID, error) {
+	command := AddToPlaylistCommand{
+		PlaylistID:     id,
+		ContentID:      contentID,
+		UserDescriptor: userDescriptor,
+	}
+
+	// retried request gets stored result even if content changed since first attempt,
+	// idempotency middleware still checks key under lock in case of concurrent retries
+	if metadata.IdempotencyKey != "" {
+		result, ok, err := service.idempotentResultFinder.FindResult(command, metadata.IdempotencyKey)
+		if err != nil || ok {
+			return result.ID, err
+		}
+	}
+
+	// content checked before dispatch to not hold playlist lock during remote call
 	err := service.contentService.ContentExists([]uuid.UUID{contentID})
 	if err != nil {
-		return uuid.UUID{}, err
+		if errors.Cause(err) != ErrContentServiceUnavailable || service.contentCheckFallback != ContentCheckFallbackPendingVerification {
+			return uuid.UUID{}, err
+		}
+		command.PendingVerification = true
 	}
 
-	var playlistItemID domain.PlaylistItemID
-	err = service.executeInUnitOfWorkWithServiceLock(playlistLockName+id.String(), func(provider RepositoryProvider) error {
-		var err2 error
+	result, err := service.commandBus.Dispatch(command, metadata)
 
-		playlistItemID, err2 = service.domainPlaylistService(provider).AddToPlaylist(
-			domain.PlaylistID(id),
-			domain.PlaylistOwnerID(userDescriptor.UserID),
-			domain.ContentID(contentID),
-		)
-		return err2
-	})
+	return result.ID, err
+}
+
+func (service *playlistService) RemoveFromPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, metadata CommandMetadata) error {
+	_, err := service.commandBus.Dispatch(RemoveFromPlaylistCommand{
+		PlaylistItemID: id,
+		UserDescriptor: userDescriptor,
+	}, metadata)
 
-	return uuid.UUID(playlistItemID), err
+	return err
 }
 
-func (service *playlistService) RemoveFromPlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor) error {
-	return service.executeInUnitOfWorkWithServiceLock(playlistLockName+id.String(), func(provider RepositoryProvider) error {
-		return service.domainPlaylistService(provider).RemoveFromPlaylist(
-			domain.PlaylistItemID(id),
-			domain.PlaylistOwnerID(userDescriptor.UserID),
-		)
-	})
+func (service *playlistService) RemovePlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor, metadata CommandMetadata) error {
+	_, err := service.commandBus.Dispatch(RemovePlaylistCommand{
+		PlaylistID:     id,
+		UserDescriptor: userDescriptor,
+	}, metadata)
+
+	return err
 }
 
-func (service *playlistService) RemovePlaylist(id uuid.UUID, userDescriptor auth.UserDescriptor) error {
-	return service.executeInUnitOfWorkWithServiceLock(playlistLockName+id.String(), func(provider RepositoryProvider) error {
-		return service.domainPlaylistService(provider).RemovePlaylist(
-			domain.PlaylistID(id),
-			domain.PlaylistOwnerID(userDescriptor.UserID),
-		)
+func (service *playlistService) RemoveUserPlaylists(userID uuid.UUID) error {
+	return service.dispatchBatches(RemoveOwnerPlaylistsCommand{
+		OwnerID:   userID,
+		BatchSize: batchSize,
 	})
 }
 
 func (service *playlistService) RemoveFromPlaylists(contentIDs []uuid.UUID) error {
-	return service.remover.RemoveFromPlaylists(contentIDs)
+	if len(contentIDs) == 0 {
+		return nil
+	}
+
+	return service.dispatchBatches(RemoveContentCommand{
+		ContentIDs: contentIDs,
+		BatchSize:  batchSize,
+	})
+}
+
+func (service *playlistService) SetContentAvailability(contentIDs []uuid.UUID, available bool) error {
+	if len(contentIDs) == 0 {
+		return nil
+	}
+
+	return service.dispatchBatches(SetContentAvailabilityCommand{
+		ContentIDs: contentIDs,
+		Available:  available,
+		BatchSize:  batchSize,
+	})
+}
+
+// dispatchBatches repeats batch command until it changes less playlists than batch size,
+// so every batch is done in own unit of work
+func (service *playlistService) dispatchBatches(command Command) error {
+	for {
+		result, err := service.commandBus.Dispatch(command, CommandMetadata{})
+		if err != nil {
+			return err
+		}
+
+		if result.Affected < batchSize {
+			return nil
+		}
+	}
+}
+
+func (service *playlistService) handleCreatePlaylist(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(CreatePlaylistCommand)
+
+	playlistID, err := service.domainPlaylistService(ctx).CreatePlaylist(
+		command.Name,
+		domain.PlaylistOwnerID(command.UserDescriptor.UserID),
+	)
+
+	return CommandResult{ID: uuid.UUID(playlistID)}, err
+}
+
+func (service *playlistService) handleSetPlaylistName(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(SetPlaylistNameCommand)
+
+	return CommandResult{}, service.domainPlaylistService(ctx).SetPlaylistName(
+		domain.PlaylistID(command.PlaylistID),
+		domain.UserID(command.UserDescriptor.UserID),
+		command.NewName,
+	)
+}
+
+func (service *playlistService) handleSetPlaylistDiscoverable(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(SetPlaylistDiscoverableCommand)
+
+	return CommandResult{}, service.domainPlaylistService(ctx).SetPlaylistDiscoverable(
+		domain.PlaylistID(command.PlaylistID),
+		domain.UserID(command.UserDescriptor.UserID),
+		command.Discoverable,
+	)
 }
 
-func (service *playlistService) executeInUnitOfWorkWithServiceLock(lockName string, f func(provider RepositoryProvider) error) error {
-	return service.executeInUnitOfWork(lockName, f)
+func (service *playlistService) handleAddToPlaylist(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(AddToPlaylistCommand)
+
+	availability := domain.PlaylistItemAvailable
+	if command.PendingVerification {
+		availability = domain.PlaylistItemPendingVerification
+	}
+
+	playlistItemID, err := service.domainPlaylistService(ctx).AddToPlaylist(
+		domain.PlaylistID(command.PlaylistID),
+		domain.UserID(command.UserDescriptor.UserID),
+		domain.ContentID(command.ContentID),
+		availability,
+	)
+
+	return CommandResult{ID: uuid.UUID(playlistItemID)}, err
 }
 
-func (service playlistService) executeInUnitOfWork(lockName string, f func(provider RepositoryProvider) error) error {
-	unitOfWork, err := service.unitOfWorkFactory.NewUnitOfWork(lockName)
+func (service *playlistService) handleRemoveFromPlaylist(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(RemoveFromPlaylistCommand)
+
+	return CommandResult{}, service.domainPlaylistService(ctx).RemoveFromPlaylist(
+		domain.PlaylistItemID(command.PlaylistItemID),
+		domain.UserID(command.UserDescriptor.UserID),
+	)
+}
+
+func (service *playlistService) handleRemovePlaylist(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(RemovePlaylistCommand)
+
+	return CommandResult{}, service.domainPlaylistService(ctx).RemovePlaylist(
+		domain.PlaylistID(command.PlaylistID),
+		domain.UserID(command.UserDescriptor.UserID),
+	)
+}
+
+func (service *playlistService) handleRemoveOwnerPlaylists(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(RemoveOwnerPlaylistsCommand)
+
+	changes, err := service.domainPlaylistService(ctx).RemoveOwnerPlaylists(
+		domain.PlaylistOwnerID(command.OwnerID),
+		command.BatchSize,
+	)
+
 	if err != nil {
-		return err
+		return CommandResult{}, err
 	}
-	defer func() {
-		err = unitOfWork.Complete(err)
-	}()
-	err = f(unitOfWork)
-	return err
+
+	ctx.playlistChanges = changes
+	return CommandResult{Affected: len(changes)}, nil
 }
 
-func (service *playlistService) domainPlaylistService(provider RepositoryProvider) domain.PlaylistService {
-	return domain.NewPlaylistService(provider.PlaylistRepository(), service.eventDispatcher)
+func (service *playlistService) handleRemoveContent(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(RemoveContentCommand)
+
+	changes, err := service.domainPlaylistService(ctx).RemoveContent(
+		convertContentIDs(command.ContentIDs),
+		command.BatchSize,
+	)
+
+	if err != nil {
+		return CommandResult{}, err
+	}
+
+	ctx.playlistChanges = changes
+	return CommandResult{Affected: len(changes)}, nil
+}
+
+func (service *playlistService) handleSetContentAvailability(ctx *CommandContext) (CommandResult, error) {
+	command := ctx.Command.(SetContentAvailabilityCommand)
+
+	availability := domain.PlaylistItemUnavailable
+	if command.Available {
+		availability = domain.PlaylistItemAvailable
+	}
+
+	changes, err := service.domainPlaylistService(ctx).SetContentAvailability(
+		convertContentIDs(command.ContentIDs),
+		availability,
+		command.BatchSize,
+	)
+
+	if err != nil {
+		return CommandResult{}, err
+	}
+
+	ctx.playlistChanges = changes
+	return CommandResult{Affected: len(changes)}, nil
+}
+
+func (service *playlistService) domainPlaylistService(ctx *CommandContext) domain.PlaylistService {
+	return domain.NewPlaylistService(ctx.PlaylistRepository(), service.eventDispatcher, service.authorizationPolicy)
+}
+
+func convertContentIDs(ids []uuid.UUID) []domain.ContentID {
+	result := make([]domain.ContentID, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, domain.ContentID(id))
+	}
+	return result
 }
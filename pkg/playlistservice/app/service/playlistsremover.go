@@ -1,7 +0,0 @@
-package service
-
-import "github.com/google/uuid"
-
-type PlaylistRemover interface {
-	RemoveFromPlaylists(contentIDs []uuid.UUID) error
-}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+)
+
+var ErrPlaylistVersionMismatch = errors.New("playlist version mismatch")
+
+// NewPlaylistVersionMiddleware rejects playlist commands when playlist changed since version expected by client,
+// must be placed after idempotency middleware so retries of applied command get stored result instead of mismatch
+func NewPlaylistVersionMiddleware() CommandMiddleware {
+	return func(next CommandHandlerFunc) CommandHandlerFunc {
+		return func(ctx *CommandContext) (CommandResult, error) {
+			_, ok := ctx.Command.(PlaylistCommand)
+			if !ok || ctx.Metadata.ExpectedPlaylistVersion == 0 {
+				return next(ctx)
+			}
+
+			playlist, err := ctx.Playlist()
+			if err != nil {
+				return CommandResult{}, err
+			}
+			// missing playlist is reported by command handler
+			if playlist == nil {
+				return next(ctx)
+			}
+
+			if playlist.Version() != ctx.Metadata.ExpectedPlaylistVersion {
+				return CommandResult{}, errors.WithStack(ErrPlaylistVersionMismatch)
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+func TestPlaylistVersionMiddleware(t *testing.T) {
+	playlist, err := domain.NewPlaylist(domain.PlaylistID(uuid.New()), "magic", domain.PlaylistOwnerID(uuid.New()))
+	assert.NoError(t, err)
+	playlist.SetName("new magic")
+
+	provider := &mockPlaylistRepositoryProvider{repo: &mockPlaylistRepository{playlist: playlist}}
+
+	handled := false
+	handler := NewPlaylistVersionMiddleware()(func(*CommandContext) (CommandResult, error) {
+		handled = true
+		return CommandResult{}, nil
+	})
+
+	dispatch := func(command Command, expectedVersion int) error {
+		handled = false
+		_, err := handler(&CommandContext{
+			Command:  command,
+			Metadata: CommandMetadata{ExpectedPlaylistVersion: expectedVersion},
+			Provider: provider,
+		})
+		return err
+	}
+
+	setName := SetPlaylistNameCommand{PlaylistID: uuid.UUID(playlist.ID()), NewName: "magic"}
+
+	{
+		assert.NoError(t, dispatch(setName, playlist.Version()))
+		assert.True(t, handled)
+	}
+
+	{
+		err := dispatch(setName, playlist.Version()-1)
+		assert.Equal(t, ErrPlaylistVersionMismatch, errors.Cause(err))
+		assert.False(t, handled, "command is not handled when playlist changed")
+	}
+
+	{
+		assert.NoError(t, dispatch(setName, 0))
+		assert.True(t, handled, "command without expected version is not checked")
+	}
+
+	{
+		err := dispatch(RemovePlaylistCommand{PlaylistID: uuid.New(), UserDescriptor: auth.UserDescriptor{UserID: uuid.New()}}, 1)
+		assert.NoError(t, err)
+		assert.True(t, handled, "missing playlist is left to command handler")
+	}
+}
+
+type mockPlaylistRepositoryProvider struct {
+	RepositoryProvider
+	repo      domain.PlaylistRepository
+	auditRepo AuditRecordRepository
+}
+
+func (provider *mockPlaylistRepositoryProvider) PlaylistRepository() domain.PlaylistRepository {
+	return provider.repo
+}
+
+func (provider *mockPlaylistRepositoryProvider) AuditRecordRepository() AuditRecordRepository {
+	return provider.auditRepo
+}
+
+type mockPlaylistRepository struct {
+	domain.PlaylistRepository
+	playlist domain.Playlist
+	finds    int
+}
+
+func (repo *mockPlaylistRepository) Find(id domain.PlaylistID) (domain.Playlist, error) {
+	repo.finds++
+	if id != repo.playlist.ID() {
+		return domain.Playlist{}, domain.ErrPlaylistNotFound
+	}
+	return repo.playlist, nil
+}
+
+func (repo *mockPlaylistRepository) Store(playlist domain.Playlist) error {
+	repo.playlist = playlist
+	return nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+// PlaylistActivity is activity of discoverable playlist during hour started at Hour
+type PlaylistActivity struct {
+	PlaylistID    uuid.UUID
+	Hour          time.Time
+	Reads         int
+	ItemAdditions int
+}
+
+type TrendingPlaylist struct {
+	PlaylistID uuid.UUID
+	Score      float64
+	// ContentTypes are types of available content in playlist, used to filter ranking
+	ContentTypes []query.ContentType
+}
+
+// TrendingPlaylistStorage keeps playlist activity and ranking of trending playlists calculated from it
+type TrendingPlaylistStorage interface {
+	// AddReads adds counts of reads by playlist to hour of given time
+	AddReads(reads map[uuid.UUID]int, at time.Time) error
+	// DiscoverableActivity returns hourly reads and item additions since given time for discoverable playlists only,
+	// item additions are counted from stored events
+	DiscoverableActivity(since time.Time) ([]PlaylistActivity, error)
+	// RemoveReadsBefore removes reads of hours which left trending window
+	RemoveReadsBefore(before time.Time) error
+	// AvailableContentIDs returns ids of available content by playlist
+	AvailableContentIDs(playlistIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
+	// ReplaceRanking replaces whole ranking by playlists ordered by rank
+	ReplaceRanking(playlists []TrendingPlaylist) error
+}
+
+// PlaylistReadCounter buffers counts of playlist reads in memory, so reads do not write to database
+type PlaylistReadCounter interface {
+	// CountRead counts read of playlist by user once an hour, repeated reads are tracked by instance which served them
+	CountRead(userID uuid.UUID, playlistID uuid.UUID)
+	// Flush stores buffered reads, reads are kept in buffer until stored
+	Flush() error
+}
+
+func NewPlaylistReadCounter(storage TrendingPlaylistStorage) PlaylistReadCounter {
+	return &playlistReadCounter{
+		storage: storage,
+		now:     time.Now,
+		reads:   map[uuid.UUID]int{},
+		readers: map[playlistReader]bool{},
+	}
+}
+
+type playlistReadCounter struct {
+	storage TrendingPlaylistStorage
+	now     func() time.Time
+	mutex   sync.Mutex
+	reads   map[uuid.UUID]int
+	// readers already counted during readersHour
+	readers     map[playlistReader]bool
+	readersHour time.Time
+}
+
+type playlistReader struct {
+	userID     uuid.UUID
+	playlistID uuid.UUID
+}
+
+func (counter *playlistReadCounter) CountRead(userID uuid.UUID, playlistID uuid.UUID) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	hour := counter.now().Truncate(time.Hour)
+	if !hour.Equal(counter.readersHour) {
+		counter.readers = map[playlistReader]bool{}
+		counter.readersHour = hour
+	}
+
+	reader := playlistReader{userID: userID, playlistID: playlistID}
+	if counter.readers[reader] {
+		return
+	}
+	counter.readers[reader] = true
+	counter.reads[playlistID]++
+}
+
+func (counter *playlistReadCounter) Flush() error {
+	counter.mutex.Lock()
+	reads := counter.reads
+	counter.reads = map[uuid.UUID]int{}
+	counter.mutex.Unlock()
+
+	if len(reads) == 0 {
+		return nil
+	}
+
+	err := counter.storage.AddReads(reads, counter.now())
+	if err != nil {
+		counter.mutex.Lock()
+		for playlistID, count := range reads {
+			counter.reads[playlistID] += count
+		}
+		counter.mutex.Unlock()
+	}
+	return err
+}
+
+type TrendingConfig struct {
+	// Window is period of activity taken into account
+	Window time.Duration
+	// HalfLife is age of activity which makes it count twice less than recent one
+	HalfLife           time.Duration
+	ReadWeight         float64
+	ItemAdditionWeight float64
+	// Size is max count of ranked playlists
+	Size int
+}
+
+// TrendingRankingLock makes ranking refreshes exclusive across service instances
+type TrendingRankingLock interface {
+	Lock() error
+	Unlock() error
+}
+
+type TrendingRanker interface {
+	// Refresh recalculates ranking of trending playlists and returns count of ranked playlists
+	Refresh() (int, error)
+}
+
+// NewTrendingRanker ranks discoverable playlists by activity decayed by its age,
+// refresh runs under lock so only one instance replaces ranking at a time
+func NewTrendingRanker(
+	storage TrendingPlaylistStorage,
+	contentQueryService query.ContentQueryService,
+	lock TrendingRankingLock,
+	config TrendingConfig,
+) TrendingRanker {
+	return &trendingRanker{
+		storage:             storage,
+		contentQueryService: contentQueryService,
+		lock:                lock,
+		config:              config,
+	}
+}
+
+type trendingRanker struct {
+	storage             TrendingPlaylistStorage
+	contentQueryService query.ContentQueryService
+	lock                TrendingRankingLock
+	config              TrendingConfig
+}
+
+func (ranker *trendingRanker) Refresh() (ranked int, err error) {
+	err = ranker.lock.Lock()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		unlockErr := ranker.lock.Unlock()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	now := time.Now()
+	since := now.Add(-ranker.config.Window)
+
+	activity, err := ranker.storage.DiscoverableActivity(since)
+	if err != nil {
+		return 0, err
+	}
+
+	playlists := ranker.rank(activity, now)
+
+	err = ranker.resolveContentTypes(playlists)
+	if err != nil {
+		return 0, err
+	}
+
+	err = ranker.storage.ReplaceRanking(playlists)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(playlists), ranker.storage.RemoveReadsBefore(since)
+}
+
+func (ranker *trendingRanker) rank(activity []PlaylistActivity, now time.Time) []TrendingPlaylist {
+	scores := map[uuid.UUID]float64{}
+	for _, hourActivity := range activity {
+		age := now.Sub(hourActivity.Hour)
+		decay := math.Pow(0.5, age.Hours()/ranker.config.HalfLife.Hours())
+		scores[hourActivity.PlaylistID] += decay * (float64(hourActivity.Reads)*ranker.config.ReadWeight +
+			float64(hourActivity.ItemAdditions)*ranker.config.ItemAdditionWeight)
+	}
+
+	playlists := make([]TrendingPlaylist, 0, len(scores))
+	for playlistID, score := range scores {
+		if score > 0 {
+			playlists = append(playlists, TrendingPlaylist{PlaylistID: playlistID, Score: score})
+		}
+	}
+
+	// ties are ordered by id to keep ranking stable between refreshes
+	sort.Slice(playlists, func(i, j int) bool {
+		if playlists[i].Score != playlists[j].Score {
+			return playlists[i].Score > playlists[j].Score
+		}
+		return playlists[i].PlaylistID.String() < playlists[j].PlaylistID.String()
+	})
+
+	if len(playlists) > ranker.config.Size {
+		playlists = playlists[:ranker.config.Size]
+	}
+	return playlists
+}
+
+func (ranker *trendingRanker) resolveContentTypes(playlists []TrendingPlaylist) error {
+	if len(playlists) == 0 {
+		return nil
+	}
+
+	playlistIDs := make([]uuid.UUID, len(playlists))
+	for i, playlist := range playlists {
+		playlistIDs[i] = playlist.PlaylistID
+	}
+
+	playlistContentIDs, err := ranker.storage.AvailableContentIDs(playlistIDs)
+	if err != nil {
+		return err
+	}
+
+	var contentIDs []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, ids := range playlistContentIDs {
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				contentIDs = append(contentIDs, id)
+			}
+		}
+	}
+
+	contents, err := ranker.contentQueryService.GetContents(context.Background(), contentIDs)
+	if err != nil {
+		return err
+	}
+
+	contentTypes := make(map[uuid.UUID]query.ContentType, len(contents))
+	for _, content := range contents {
+		contentTypes[content.ID] = content.Type
+	}
+
+	for i, playlist := range playlists {
+		types := map[query.ContentType]bool{}
+		for _, contentID := range playlistContentIDs[playlist.PlaylistID] {
+			contentType, ok := contentTypes[contentID]
+			if ok && !types[contentType] {
+				types[contentType] = true
+				playlists[i].ContentTypes = append(playlists[i].ContentTypes, contentType)
+			}
+		}
+		sort.Slice(playlists[i].ContentTypes, func(a, b int) bool {
+			return playlists[i].ContentTypes[a] < playlists[i].ContentTypes[b]
+		})
+	}
+
+	return nil
+}
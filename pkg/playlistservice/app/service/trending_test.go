@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+func TestTrendingRanker_Refresh(t *testing.T) {
+	now := time.Now().Truncate(time.Hour)
+	playlistIDs := newSortedUUIDs(4)
+	songID, podcastID, removedContentID := uuid.New(), uuid.New(), uuid.New()
+
+	storage := &mockTrendingPlaylistStorage{
+		activity: []PlaylistActivity{
+			{PlaylistID: playlistIDs[0], Hour: now, Reads: 10},
+			{PlaylistID: playlistIDs[1], Hour: now, Reads: 2, ItemAdditions: 3},
+			{PlaylistID: playlistIDs[2], Hour: now.Add(-48 * time.Hour), Reads: 20},
+			{PlaylistID: playlistIDs[3], Hour: now, Reads: 1},
+			{PlaylistID: playlistIDs[3], Hour: now.Add(-time.Hour), Reads: 1},
+		},
+		contentIDs: map[uuid.UUID][]uuid.UUID{
+			playlistIDs[0]: {songID, podcastID},
+			playlistIDs[1]: {podcastID, removedContentID},
+		},
+	}
+	contentQueryService := &mockContentQueryService{contents: []query.ContentView{
+		{ID: songID, Type: query.ContentTypeSong},
+		{ID: podcastID, Type: query.ContentTypePodcast},
+	}}
+
+	lock := &mockTrendingRankingLock{lockErr: errors.New("lock is held by another instance")}
+
+	ranker := NewTrendingRanker(storage, contentQueryService, lock, TrendingConfig{
+		Window:             72 * time.Hour,
+		HalfLife:           24 * time.Hour,
+		ReadWeight:         1,
+		ItemAdditionWeight: 5,
+		Size:               3,
+	})
+
+	_, err := ranker.Refresh()
+	assert.Equal(t, lock.lockErr, err)
+	assert.Nil(t, storage.ranking, "ranking is not replaced without lock")
+
+	lock.lockErr = nil
+	ranked, err := ranker.Refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ranked)
+	assert.False(t, lock.locked, "lock is released after refresh")
+
+	rankedIDs := make([]uuid.UUID, len(storage.ranking))
+	for i, playlist := range storage.ranking {
+		rankedIDs[i] = playlist.PlaylistID
+	}
+	assert.Equal(t, []uuid.UUID{playlistIDs[1], playlistIDs[0], playlistIDs[2]}, rankedIDs, "item additions weigh more and old activity decays")
+
+	assert.Equal(t, []query.ContentType{query.ContentTypePodcast}, storage.ranking[0].ContentTypes, "content missing in content service is skipped")
+	assert.Equal(t, []query.ContentType{query.ContentTypeSong, query.ContentTypePodcast}, storage.ranking[1].ContentTypes)
+	assert.Empty(t, storage.ranking[2].ContentTypes)
+
+	assert.WithinDuration(t, time.Now().Add(-72*time.Hour), storage.readsRemovedBefore, time.Minute)
+	assert.True(t, storage.activitySince.Equal(storage.readsRemovedBefore))
+}
+
+func TestPlaylistReadCounter_Flush(t *testing.T) {
+	storage := &mockTrendingPlaylistStorage{addReadsErr: errors.New("database is down")}
+	counter := NewPlaylistReadCounter(storage)
+	playlistID := uuid.New()
+
+	counter.CountRead(uuid.New(), playlistID)
+	counter.CountRead(uuid.New(), playlistID)
+
+	assert.Error(t, counter.Flush())
+	assert.Nil(t, storage.reads, "reads are not stored")
+
+	storage.addReadsErr = nil
+	counter.CountRead(uuid.New(), playlistID)
+
+	assert.NoError(t, counter.Flush())
+	assert.Equal(t, map[uuid.UUID]int{playlistID: 3}, storage.reads, "reads are kept in buffer until stored")
+
+	storage.reads = nil
+	assert.NoError(t, counter.Flush())
+	assert.Nil(t, storage.reads, "empty buffer is not stored")
+}
+
+func TestPlaylistReadCounter_CountRead(t *testing.T) {
+	storage := &mockTrendingPlaylistStorage{}
+	counter := NewPlaylistReadCounter(storage)
+	now := time.Date(2026, 10, 19, 10, 15, 0, 0, time.UTC)
+	counter.(*playlistReadCounter).now = func() time.Time {
+		return now
+	}
+	userID, anotherUserID := uuid.New(), uuid.New()
+	playlistID, anotherPlaylistID := uuid.New(), uuid.New()
+
+	{
+		counter.CountRead(userID, playlistID)
+		counter.CountRead(userID, playlistID)
+		counter.CountRead(anotherUserID, playlistID)
+		counter.CountRead(userID, anotherPlaylistID)
+
+		assert.NoError(t, counter.Flush())
+		assert.Equal(t, map[uuid.UUID]int{playlistID: 2, anotherPlaylistID: 1}, storage.reads, "repeated reads of user are counted once")
+		assert.Equal(t, now, storage.readsAt)
+	}
+
+	{
+		storage.reads = nil
+		now = now.Add(30 * time.Minute)
+		counter.CountRead(userID, playlistID)
+
+		assert.NoError(t, counter.Flush())
+		assert.Nil(t, storage.reads, "flush does not reset readers of hour")
+	}
+
+	{
+		now = now.Add(30 * time.Minute)
+		counter.CountRead(userID, playlistID)
+
+		assert.NoError(t, counter.Flush())
+		assert.Equal(t, map[uuid.UUID]int{playlistID: 1}, storage.reads, "read is counted again next hour")
+	}
+}
+
+type mockTrendingPlaylistStorage struct {
+	activity           []PlaylistActivity
+	contentIDs         map[uuid.UUID][]uuid.UUID
+	ranking            []TrendingPlaylist
+	reads              map[uuid.UUID]int
+	readsAt            time.Time
+	addReadsErr        error
+	activitySince      time.Time
+	readsRemovedBefore time.Time
+}
+
+func (storage *mockTrendingPlaylistStorage) AddReads(reads map[uuid.UUID]int, at time.Time) error {
+	if storage.addReadsErr != nil {
+		return storage.addReadsErr
+	}
+	storage.reads = reads
+	storage.readsAt = at
+	return nil
+}
+
+func (storage *mockTrendingPlaylistStorage) DiscoverableActivity(since time.Time) ([]PlaylistActivity, error) {
+	storage.activitySince = since
+	return storage.activity, nil
+}
+
+func (storage *mockTrendingPlaylistStorage) RemoveReadsBefore(before time.Time) error {
+	storage.readsRemovedBefore = before
+	return nil
+}
+
+func (storage *mockTrendingPlaylistStorage) AvailableContentIDs(playlistIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	result := map[uuid.UUID][]uuid.UUID{}
+	for _, playlistID := range playlistIDs {
+		result[playlistID] = storage.contentIDs[playlistID]
+	}
+	return result, nil
+}
+
+func (storage *mockTrendingPlaylistStorage) ReplaceRanking(playlists []TrendingPlaylist) error {
+	storage.ranking = playlists
+	return nil
+}
+
+type mockTrendingRankingLock struct {
+	lockErr error
+	locked  bool
+}
+
+func (lock *mockTrendingRankingLock) Lock() error {
+	if lock.lockErr != nil {
+		return lock.lockErr
+	}
+	lock.locked = true
+	return nil
+}
+
+func (lock *mockTrendingRankingLock) Unlock() error {
+	lock.locked = false
+	return nil
+}
+
+type mockContentQueryService struct {
+	contents []query.ContentView
+}
+
+func (service *mockContentQueryService) GetContents(_ context.Context, contentIDs []uuid.UUID) ([]query.ContentView, error) {
+	var result []query.ContentView
+	for _, content := range service.contents {
+		for _, id := range contentIDs {
+			if content.ID == id {
+				result = append(result, content)
+			}
+		}
+	}
+	return result, nil
+}
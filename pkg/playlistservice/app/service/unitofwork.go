@@ -8,6 +8,9 @@ type UnitOfWorkFactory interface {
 
 type RepositoryProvider interface {
 	PlaylistRepository() domain.PlaylistRepository
+	IdempotencyRecordRepository() IdempotencyRecordRepository
+	AuditRecordRepository() AuditRecordRepository
+	DataExportRepository() DataExportRepository
 }
 
 type UnitOfWork interface {
@@ -0,0 +1,20 @@
+package service
+
+// NewUnitOfWorkMiddleware executes rest of pipeline in unit of work holding command lock
+func NewUnitOfWorkMiddleware(unitOfWorkFactory UnitOfWorkFactory) CommandMiddleware {
+	return func(next CommandHandlerFunc) CommandHandlerFunc {
+		return func(ctx *CommandContext) (result CommandResult, err error) {
+			unitOfWork, err := unitOfWorkFactory.NewUnitOfWork(ctx.Command.LockName())
+			if err != nil {
+				return CommandResult{}, err
+			}
+			defer func() {
+				err = unitOfWork.Complete(err)
+			}()
+
+			ctx.Provider = unitOfWork
+
+			return next(ctx)
+		}
+	}
+}
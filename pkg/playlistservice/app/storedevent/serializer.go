@@ -7,6 +7,7 @@ import (
 	commondomain "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/domain"
 	"github.com/google/uuid"
 
+	"playlistservice/pkg/playlistservice/app/service"
 	"playlistservice/pkg/playlistservice/domain"
 )
 
@@ -62,15 +63,25 @@ func serializeEvent(event commondomain.Event) (eventPayload interface{}) {
 			PlaylistID: uuid.UUID(currEvent.PlaylistID),
 			Name:       currEvent.NewName,
 		}
+	case domain.PlaylistDiscoverabilityChanged:
+		eventPayload = struct {
+			PlaylistID   uuid.UUID `json:"playlist_id"`
+			Discoverable bool      `json:"discoverable"`
+		}{
+			PlaylistID:   uuid.UUID(currEvent.PlaylistID),
+			Discoverable: currEvent.Discoverable,
+		}
 	case domain.PlaylistItemAdded:
 		eventPayload = struct {
 			PlaylistID     uuid.UUID `json:"playlist_id"`
 			PlaylistItemID uuid.UUID `json:"playlist_item_id"`
 			ContentID      uuid.UUID `json:"content_id"`
+			Availability   int       `json:"availability"`
 		}{
 			PlaylistID:     uuid.UUID(currEvent.PlaylistID),
 			PlaylistItemID: uuid.UUID(currEvent.PlaylistItemID),
 			ContentID:      uuid.UUID(currEvent.ContentID),
+			Availability:   int(currEvent.Availability),
 		}
 	case domain.PlaylistItemRemoved:
 		eventPayload = struct {
@@ -80,6 +91,18 @@ func serializeEvent(event commondomain.Event) (eventPayload interface{}) {
 			PlaylistID:     uuid.UUID(currEvent.PlaylistID),
 			PlaylistItemID: uuid.UUID(currEvent.PlaylistItemID),
 		}
+	case domain.PlaylistItemAvailabilityChanged:
+		eventPayload = struct {
+			PlaylistID     uuid.UUID `json:"playlist_id"`
+			PlaylistItemID uuid.UUID `json:"playlist_item_id"`
+			ContentID      uuid.UUID `json:"content_id"`
+			Availability   int       `json:"availability"`
+		}{
+			PlaylistID:     uuid.UUID(currEvent.PlaylistID),
+			PlaylistItemID: uuid.UUID(currEvent.PlaylistItemID),
+			ContentID:      uuid.UUID(currEvent.ContentID),
+			Availability:   int(currEvent.Availability),
+		}
 	case domain.PlaylistRemoved:
 		eventPayload = struct {
 			PlaylistID uuid.UUID `json:"playlist_id"`
@@ -88,6 +111,14 @@ func serializeEvent(event commondomain.Event) (eventPayload interface{}) {
 			PlaylistID: uuid.UUID(currEvent.PlaylistID),
 			OwnerID:    uuid.UUID(currEvent.OwnerID),
 		}
+	case service.DataExportCompletedEvent:
+		eventPayload = struct {
+			ExportID uuid.UUID `json:"export_id"`
+			UserID   uuid.UUID `json:"user_id"`
+		}{
+			ExportID: currEvent.ExportID,
+			UserID:   currEvent.UserID,
+		}
 	}
 	return
 }
@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type (
+	UserID uuid.UUID
+	Role   string
+	Action string
+)
+
+const (
+	RoleOwner        Role = "owner"
+	RoleCollaborator Role = "collaborator"
+	RoleModerator    Role = "moderator"
+	RoleAdmin        Role = "admin"
+)
+
+const (
+	ActionAny                     Action = "*"
+	ActionCreatePlaylist          Action = "create_playlist"
+	ActionViewPlaylist            Action = "view_playlist"
+	ActionSetPlaylistName         Action = "set_playlist_name"
+	ActionAddToPlaylist           Action = "add_to_playlist"
+	ActionRemoveFromPlaylist      Action = "remove_from_playlist"
+	ActionRemovePlaylist          Action = "remove_playlist"
+	ActionViewAuditLog            Action = "view_audit_log"
+	ActionSetPlaylistDiscoverable Action = "set_playlist_discoverable"
+)
+
+var (
+	ErrAccessDenied = errors.New("access denied")
+)
+
+type AccessDeniedError struct {
+	Reason string
+}
+
+func (err *AccessDeniedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAccessDenied.Error(), err.Reason)
+}
+
+func (err *AccessDeniedError) Is(target error) bool {
+	return target == ErrAccessDenied
+}
+
+type AuthorizationTarget struct {
+	PlaylistID PlaylistID
+	OwnerID    PlaylistOwnerID
+	// Discoverable playlists are shown in public feeds, so any user may view them
+	Discoverable bool
+}
+
+type AuthorizationPolicy interface {
+	Authorize(userID UserID, action Action, target AuthorizationTarget) error
+	// AuthorizeGlobalRole checks that user has one of roles assigned for every playlist
+	AuthorizeGlobalRole(userID UserID, roles ...Role) error
+	// AssignedPlaylistIDs returns playlists shared with user by roles assigned for single playlist which permit action
+	AssignedPlaylistIDs(userID UserID, action Action) []PlaylistID
+}
+
+// RoleAssignment grants role to user, for every playlist when PlaylistID is nil
+type RoleAssignment struct {
+	UserID     UserID
+	Role       Role
+	PlaylistID *PlaylistID
+}
+
+type AuthorizationRules struct {
+	Permissions map[Role][]Action
+	Assignments []RoleAssignment
+}
+
+func DefaultAuthorizationRules() AuthorizationRules {
+	return AuthorizationRules{
+		Permissions: map[Role][]Action{
+			RoleOwner: {ActionAny},
+			RoleCollaborator: {
+				ActionViewPlaylist,
+				ActionAddToPlaylist,
+				ActionRemoveFromPlaylist,
+			},
+			RoleModerator: {
+				ActionViewPlaylist,
+				ActionSetPlaylistName,
+				ActionRemoveFromPlaylist,
+			},
+			RoleAdmin: {ActionAny},
+		},
+	}
+}
+
+func NewRuleBasedAuthorizationPolicy(rules AuthorizationRules) AuthorizationPolicy {
+	return &ruleBasedAuthorizationPolicy{rules: rules}
+}
+
+type ruleBasedAuthorizationPolicy struct {
+	rules AuthorizationRules
+}
+
+func (policy *ruleBasedAuthorizationPolicy) Authorize(userID UserID, action Action, target AuthorizationTarget) error {
+	if action == ActionViewPlaylist && target.Discoverable {
+		return nil
+	}
+
+	roles := policy.roles(userID, target)
+	if len(roles) == 0 {
+		return &AccessDeniedError{Reason: fmt.Sprintf(
+			"user %s has no role on playlist %s",
+			uuid.UUID(userID),
+			uuid.UUID(target.PlaylistID),
+		)}
+	}
+
+	for _, role := range roles {
+		if policy.permits(role, action) {
+			return nil
+		}
+	}
+
+	return &AccessDeniedError{Reason: fmt.Sprintf(
+		"roles [%s] of user %s do not permit %s on playlist %s",
+		joinRoles(roles),
+		uuid.UUID(userID),
+		action,
+		uuid.UUID(target.PlaylistID),
+	)}
+}
+
+func (policy *ruleBasedAuthorizationPolicy) AuthorizeGlobalRole(userID UserID, roles ...Role) error {
+	for _, assignment := range policy.rules.Assignments {
+		if assignment.UserID != userID || assignment.PlaylistID != nil {
+			continue
+		}
+		for _, role := range roles {
+			if assignment.Role == role {
+				return nil
+			}
+		}
+	}
+
+	return &AccessDeniedError{Reason: fmt.Sprintf(
+		"user %s has none of global roles [%s]",
+		uuid.UUID(userID),
+		joinRoles(roles),
+	)}
+}
+
+func (policy *ruleBasedAuthorizationPolicy) AssignedPlaylistIDs(userID UserID, action Action) []PlaylistID {
+	var playlistIDs []PlaylistID
+	seen := map[PlaylistID]bool{}
+	for _, assignment := range policy.rules.Assignments {
+		if assignment.UserID != userID || assignment.PlaylistID == nil || seen[*assignment.PlaylistID] {
+			continue
+		}
+		if !policy.permits(assignment.Role, action) {
+			continue
+		}
+		seen[*assignment.PlaylistID] = true
+		playlistIDs = append(playlistIDs, *assignment.PlaylistID)
+	}
+	return playlistIDs
+}
+
+func (policy *ruleBasedAuthorizationPolicy) roles(userID UserID, target AuthorizationTarget) []Role {
+	var roles []Role
+	if PlaylistOwnerID(userID) == target.OwnerID {
+		roles = append(roles, RoleOwner)
+	}
+
+	for _, assignment := range policy.rules.Assignments {
+		if assignment.UserID != userID {
+			continue
+		}
+		if assignment.PlaylistID != nil && *assignment.PlaylistID != target.PlaylistID {
+			continue
+		}
+		roles = append(roles, assignment.Role)
+	}
+
+	return roles
+}
+
+func (policy *ruleBasedAuthorizationPolicy) permits(role Role, action Action) bool {
+	for _, permitted := range policy.rules.Permissions[role] {
+		if permitted == ActionAny || permitted == action {
+			return true
+		}
+	}
+	return false
+}
+
+func joinRoles(roles []Role) string {
+	result := make([]string, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, string(role))
+	}
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
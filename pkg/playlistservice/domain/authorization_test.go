@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleBasedAuthorizationPolicy_Authorize(t *testing.T) {
+	owner := UserID(uuid.New())
+	collaborator := UserID(uuid.New())
+	moderator := UserID(uuid.New())
+	admin := UserID(uuid.New())
+	stranger := UserID(uuid.New())
+
+	target := AuthorizationTarget{
+		PlaylistID: PlaylistID(uuid.New()),
+		OwnerID:    PlaylistOwnerID(owner),
+	}
+	anotherTarget := AuthorizationTarget{
+		PlaylistID: PlaylistID(uuid.New()),
+		OwnerID:    PlaylistOwnerID(uuid.New()),
+	}
+
+	rules := DefaultAuthorizationRules()
+	rules.Assignments = []RoleAssignment{
+		{UserID: collaborator, Role: RoleCollaborator, PlaylistID: &target.PlaylistID},
+		{UserID: moderator, Role: RoleModerator},
+		{UserID: admin, Role: RoleAdmin},
+	}
+
+	policy := NewRuleBasedAuthorizationPolicy(rules)
+
+	{
+		assert.NoError(t, policy.Authorize(owner, ActionRemovePlaylist, target))
+		assert.True(t, errors.Is(policy.Authorize(owner, ActionRemovePlaylist, anotherTarget), ErrAccessDenied))
+	}
+
+	{
+		assert.NoError(t, policy.Authorize(collaborator, ActionAddToPlaylist, target))
+		assert.True(t, errors.Is(policy.Authorize(collaborator, ActionAddToPlaylist, anotherTarget), ErrAccessDenied), "collaborator role is scoped to playlist")
+
+		err := policy.Authorize(collaborator, ActionRemovePlaylist, target)
+		assert.True(t, errors.Is(err, ErrAccessDenied))
+		assert.Contains(t, err.Error(), "roles [collaborator]")
+		assert.Contains(t, err.Error(), string(ActionRemovePlaylist))
+	}
+
+	{
+		assert.NoError(t, policy.Authorize(moderator, ActionSetPlaylistName, anotherTarget))
+		assert.True(t, errors.Is(policy.Authorize(moderator, ActionRemovePlaylist, anotherTarget), ErrAccessDenied))
+	}
+
+	{
+		assert.NoError(t, policy.Authorize(admin, ActionRemovePlaylist, anotherTarget))
+	}
+
+	{
+		assert.NoError(t, policy.AuthorizeGlobalRole(admin, RoleAdmin, RoleModerator))
+		assert.NoError(t, policy.AuthorizeGlobalRole(moderator, RoleAdmin, RoleModerator))
+		assert.True(t, errors.Is(policy.AuthorizeGlobalRole(moderator, RoleAdmin), ErrAccessDenied))
+		assert.True(t, errors.Is(policy.AuthorizeGlobalRole(collaborator, RoleCollaborator), ErrAccessDenied), "scoped roles are not global")
+		assert.True(t, errors.Is(policy.AuthorizeGlobalRole(owner, RoleOwner), ErrAccessDenied))
+	}
+
+	{
+		assert.Equal(t, []PlaylistID{target.PlaylistID}, policy.AssignedPlaylistIDs(collaborator, ActionViewPlaylist))
+		assert.Empty(t, policy.AssignedPlaylistIDs(collaborator, ActionRemovePlaylist), "roles which do not permit action do not share playlist")
+		assert.Empty(t, policy.AssignedPlaylistIDs(moderator, ActionViewPlaylist), "global roles do not share playlists")
+		assert.Empty(t, policy.AssignedPlaylistIDs(owner, ActionViewPlaylist))
+	}
+
+	{
+		err := policy.Authorize(stranger, ActionViewPlaylist, target)
+		assert.True(t, errors.Is(err, ErrAccessDenied))
+		assert.Contains(t, err.Error(), "has no role")
+	}
+
+	{
+		discoverableTarget := target
+		discoverableTarget.Discoverable = true
+
+		assert.NoError(t, policy.Authorize(stranger, ActionViewPlaylist, discoverableTarget), "discoverable playlist is viewed by anyone")
+		assert.True(t, errors.Is(policy.Authorize(stranger, ActionAddToPlaylist, discoverableTarget), ErrAccessDenied), "discoverable playlist is changed by roles only")
+	}
+}
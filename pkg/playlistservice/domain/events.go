@@ -68,10 +68,20 @@ func (p PlaylistNameChanged) ID() string {
 	return "playlist_name_changed"
 }
 
+type PlaylistDiscoverabilityChanged struct {
+	PlaylistID   PlaylistID
+	Discoverable bool
+}
+
+func (p PlaylistDiscoverabilityChanged) ID() string {
+	return "playlist_discoverability_changed"
+}
+
 type PlaylistItemAdded struct {
 	PlaylistID     PlaylistID
 	PlaylistItemID PlaylistItemID
 	ContentID      ContentID
+	Availability   PlaylistItemAvailability
 }
 
 func (p PlaylistItemAdded) ID() string {
@@ -87,6 +97,17 @@ func (p PlaylistItemRemoved) ID() string {
 	return "playlist_item_removed"
 }
 
+type PlaylistItemAvailabilityChanged struct {
+	PlaylistID     PlaylistID
+	PlaylistItemID PlaylistItemID
+	ContentID      ContentID
+	Availability   PlaylistItemAvailability
+}
+
+func (p PlaylistItemAvailabilityChanged) ID() string {
+	return "playlist_item_availability_changed"
+}
+
 type PlaylistRemoved struct {
 	PlaylistID PlaylistID
 	OwnerID    PlaylistOwnerID
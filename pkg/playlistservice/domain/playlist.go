@@ -21,6 +21,16 @@ type (
 	ContentID       uuid.UUID
 )
 
+type PlaylistItemAvailability int
+
+const (
+	PlaylistItemAvailable PlaylistItemAvailability = iota
+	// PlaylistItemPendingVerification marks item added while content couldn't be checked
+	PlaylistItemPendingVerification
+	// PlaylistItemUnavailable marks item which content is private now, item becomes available when content is public again
+	PlaylistItemUnavailable
+)
+
 func NewPlaylist(id PlaylistID, name string, ownerID PlaylistOwnerID) (Playlist, error) {
 	if name == "" {
 		return Playlist{}, ErrEmptyPlaylistName
@@ -35,6 +45,7 @@ func NewPlaylist(id PlaylistID, name string, ownerID PlaylistOwnerID) (Playlist,
 		items:     map[PlaylistItemID]PlaylistItem{},
 		createdAt: &now,
 		updatedAt: &now,
+		version:   1,
 	}, nil
 }
 
@@ -45,6 +56,10 @@ type Playlist struct {
 	items     map[PlaylistItemID]PlaylistItem
 	createdAt *time.Time
 	updatedAt *time.Time
+	// version is incremented by every change of playlist, clients use it for conditional requests
+	version int
+	// discoverable playlists are shown in public discovery feeds, owners opt in playlists explicitly
+	discoverable bool
 }
 
 func (playlist *Playlist) ID() PlaylistID {
@@ -57,6 +72,7 @@ func (playlist *Playlist) Name() string {
 
 func (playlist *Playlist) SetName(newName string) {
 	playlist.name = newName
+	playlist.touch()
 }
 
 func (playlist *Playlist) OwnerID() PlaylistOwnerID {
@@ -71,25 +87,52 @@ func (playlist *Playlist) UpdatedAt() *time.Time {
 	return playlist.updatedAt
 }
 
+func (playlist *Playlist) Version() int {
+	return playlist.version
+}
+
+func (playlist *Playlist) Discoverable() bool {
+	return playlist.discoverable
+}
+
+func (playlist *Playlist) SetDiscoverable(discoverable bool) {
+	playlist.discoverable = discoverable
+	playlist.touch()
+}
+
 func (playlist *Playlist) Items() map[PlaylistItemID]PlaylistItem {
 	return playlist.items
 }
 
-func (playlist *Playlist) AddItem(id PlaylistItemID, contentID ContentID) {
+// Clone returns copy of playlist which doesn't share items with original
+func (playlist *Playlist) Clone() Playlist {
+	clone := *playlist
+	clone.items = make(map[PlaylistItemID]PlaylistItem, len(playlist.items))
+	for id, item := range playlist.items {
+		clone.items[id] = item
+	}
+	return clone
+}
+
+func (playlist *Playlist) AddItem(id PlaylistItemID, contentID ContentID, availability PlaylistItemAvailability) {
 	playlistItem, ok := playlist.items[id]
 	if ok {
 		playlistItem.contentID = contentID
+		playlistItem.availability = availability
+		playlist.items[id] = playlistItem
+		playlist.touch()
 		return
 	}
 
 	now := time.Now()
 
 	playlist.items[id] = PlaylistItem{
-		id:        id,
-		contentID: contentID,
-		createdAt: &now,
+		id:           id,
+		contentID:    contentID,
+		availability: availability,
+		createdAt:    &now,
 	}
-	playlist.updatedAt = &now
+	playlist.touch()
 }
 
 func (playlist *Playlist) RemoveItem(itemID PlaylistItemID) error {
@@ -99,17 +142,62 @@ func (playlist *Playlist) RemoveItem(itemID PlaylistItemID) error {
 	}
 
 	delete(playlist.items, itemID)
+	playlist.touch()
+
+	return nil
+}
+
+// RemoveContent removes items with given content and returns IDs of removed items
+func (playlist *Playlist) RemoveContent(contentID ContentID) []PlaylistItemID {
+	var removedItemIDs []PlaylistItemID
+	for id, item := range playlist.items {
+		if item.contentID == contentID {
+			removedItemIDs = append(removedItemIDs, id)
+		}
+	}
 
+	for _, id := range removedItemIDs {
+		delete(playlist.items, id)
+	}
+
+	if len(removedItemIDs) != 0 {
+		playlist.touch()
+	}
+
+	return removedItemIDs
+}
+
+// SetContentAvailability changes availability of items with given content and returns IDs of changed items
+func (playlist *Playlist) SetContentAvailability(contentID ContentID, availability PlaylistItemAvailability) []PlaylistItemID {
+	var changedItemIDs []PlaylistItemID
+	for id, item := range playlist.items {
+		if item.contentID != contentID || item.availability == availability {
+			continue
+		}
+
+		item.availability = availability
+		playlist.items[id] = item
+		changedItemIDs = append(changedItemIDs, id)
+	}
+
+	if len(changedItemIDs) != 0 {
+		playlist.touch()
+	}
+
+	return changedItemIDs
+}
+
+func (playlist *Playlist) touch() {
 	now := time.Now()
 	playlist.updatedAt = &now
-
-	return nil
+	playlist.version++
 }
 
 type PlaylistItem struct {
-	id        PlaylistItemID
-	contentID ContentID
-	createdAt *time.Time
+	id           PlaylistItemID
+	contentID    ContentID
+	availability PlaylistItemAvailability
+	createdAt    *time.Time
 }
 
 func (item *PlaylistItem) ID() PlaylistItemID {
@@ -120,19 +208,29 @@ func (item *PlaylistItem) ContentID() ContentID {
 	return item.contentID
 }
 
+func (item *PlaylistItem) Availability() PlaylistItemAvailability {
+	return item.availability
+}
+
 func (item *PlaylistItem) CreatedAt() *time.Time {
 	return item.createdAt
 }
 
 type PlaylistSpecification struct {
+	OwnerIDs   []PlaylistOwnerID
 	ContentIDs []ContentID
+	// ExceptAvailability excludes items in given state from matching by ContentIDs
+	ExceptAvailability *PlaylistItemAvailability
+	Limit              int
 }
 
 type PlaylistRepository interface {
 	NewID() PlaylistID
 	NewPlaylistItemID() PlaylistItemID
+	// Find and FindByItemID lock found playlist until unit of work completes
 	Find(id PlaylistID) (Playlist, error)
 	FindByItemID(playlistItemID PlaylistItemID) (Playlist, error)
+	FindAll(spec PlaylistSpecification) ([]Playlist, error)
 	Store(playlist Playlist) error
 	Remove(id PlaylistID) error
 }
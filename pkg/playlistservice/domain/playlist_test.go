@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
@@ -15,7 +16,7 @@ func TestPlaylistService_CreatePlaylist(t *testing.T) {
 	playlistRepo := newMockPlaylistRepo()
 	eventDispatcher := newMockEventDispatcher()
 
-	playlistService := NewPlaylistService(playlistRepo, eventDispatcher)
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
 
 	{
 		newPlaylistName := playlistName
@@ -36,13 +37,23 @@ func TestPlaylistService_CreatePlaylist(t *testing.T) {
 		assert.Equal(t, len(eventDispatcher.events), 1)
 		assert.IsType(t, PlaylistCreated{}, eventDispatcher.events[0])
 	}
+
+	{
+		rules := DefaultAuthorizationRules()
+		rules.Permissions[RoleOwner] = []Action{ActionViewPlaylist}
+		playlistService = NewPlaylistService(playlistRepo, eventDispatcher, NewRuleBasedAuthorizationPolicy(rules))
+
+		_, err := playlistService.CreatePlaylist(playlistName, PlaylistOwnerID(uuid.New()))
+		assert.True(t, errors.Is(err, ErrAccessDenied), "policy may deny creation of playlists")
+		assert.Equal(t, len(playlistRepo.playlists), 1)
+	}
 }
 
 func TestPlaylistService_SetPlaylistName(t *testing.T) {
 	playlistRepo := newMockPlaylistRepo()
 	eventDispatcher := newMockEventDispatcher()
 
-	playlistService := NewPlaylistService(playlistRepo, eventDispatcher)
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
 
 	{
 		playlistName := playlistName
@@ -52,21 +63,26 @@ func TestPlaylistService_SetPlaylistName(t *testing.T) {
 		assert.NoError(t, err)
 
 		newPlaylistName := "new-" + playlistName
-		err = playlistService.SetPlaylistName(playlistID, playlistOwner, newPlaylistName)
+		err = playlistService.SetPlaylistName(playlistID, UserID(playlistOwner), newPlaylistName)
 		assert.NoError(t, err)
 
 		playlist, err := playlistRepo.Find(playlistID)
 		assert.NoError(t, err)
 
 		assert.Equal(t, newPlaylistName, playlist.Name())
+		assert.Equal(t, 2, playlist.Version(), "rename changes playlist version")
 
 		assert.Equal(t, len(eventDispatcher.events), 2)
 		assert.IsType(t, PlaylistNameChanged{}, eventDispatcher.events[1])
 
-		err = playlistService.SetPlaylistName(playlistID, playlistOwner, newPlaylistName)
+		err = playlistService.SetPlaylistName(playlistID, UserID(playlistOwner), newPlaylistName)
 		assert.NoError(t, err)
 
 		assert.Equal(t, len(eventDispatcher.events), 2, "when set current name to playlist no event dispatched")
+
+		playlist, err = playlistRepo.Find(playlistID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, playlist.Version(), "when set current name to playlist version is kept")
 	}
 
 	{
@@ -78,8 +94,8 @@ func TestPlaylistService_SetPlaylistName(t *testing.T) {
 		assert.NoError(t, err)
 
 		newPlaylistName := "new-" + playlistName
-		err = playlistService.SetPlaylistName(playlistID, anotherPlaylistOwner, newPlaylistName)
-		assert.EqualError(t, err, ErrOnlyOwnerCanManagePlaylist.Error())
+		err = playlistService.SetPlaylistName(playlistID, UserID(anotherPlaylistOwner), newPlaylistName)
+		assert.True(t, errors.Is(err, ErrAccessDenied))
 
 		playlist, err := playlistRepo.Find(playlistID)
 		assert.NoError(t, err)
@@ -88,11 +104,54 @@ func TestPlaylistService_SetPlaylistName(t *testing.T) {
 	}
 }
 
+func TestPlaylistService_SetPlaylistDiscoverable(t *testing.T) {
+	playlistRepo := newMockPlaylistRepo()
+	eventDispatcher := newMockEventDispatcher()
+
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
+
+	playlistOwner := PlaylistOwnerID(uuid.New())
+
+	playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
+	assert.NoError(t, err)
+
+	{
+		playlist, err := playlistRepo.Find(playlistID)
+		assert.NoError(t, err)
+		assert.False(t, playlist.Discoverable(), "playlists are not discoverable until owner opts in")
+	}
+
+	{
+		err = playlistService.SetPlaylistDiscoverable(playlistID, UserID(playlistOwner), true)
+		assert.NoError(t, err)
+
+		playlist, err := playlistRepo.Find(playlistID)
+		assert.NoError(t, err)
+		assert.True(t, playlist.Discoverable())
+
+		assert.Equal(t, 2, len(eventDispatcher.events))
+		assert.Equal(t, PlaylistDiscoverabilityChanged{PlaylistID: playlistID, Discoverable: true}, eventDispatcher.events[1])
+
+		err = playlistService.SetPlaylistDiscoverable(playlistID, UserID(playlistOwner), true)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(eventDispatcher.events), "when set current discoverability no event dispatched")
+	}
+
+	{
+		err = playlistService.SetPlaylistDiscoverable(playlistID, UserID(uuid.New()), false)
+		assert.True(t, errors.Is(err, ErrAccessDenied))
+
+		playlist, err := playlistRepo.Find(playlistID)
+		assert.NoError(t, err)
+		assert.True(t, playlist.Discoverable(), "playlist discoverability didnt change")
+	}
+}
+
 func TestPlaylistService_AddToPlaylist(t *testing.T) {
 	playlistRepo := newMockPlaylistRepo()
 	eventDispatcher := newMockEventDispatcher()
 
-	playlistService := NewPlaylistService(playlistRepo, eventDispatcher)
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
 
 	{
 		playlistName := playlistName
@@ -102,7 +161,7 @@ func TestPlaylistService_AddToPlaylist(t *testing.T) {
 		playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
 		assert.NoError(t, err)
 
-		playlistItemID, err := playlistService.AddToPlaylist(playlistID, playlistOwner, content)
+		playlistItemID, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content, PlaylistItemAvailable)
 		assert.NoError(t, err)
 
 		playlist, ok := playlistRepo.playlists[playlistID]
@@ -112,19 +171,20 @@ func TestPlaylistService_AddToPlaylist(t *testing.T) {
 		assert.Equal(t, true, ok)
 
 		assert.Equal(t, content, playlistItem.ContentID())
+		assert.Equal(t, PlaylistItemAvailable, playlistItem.Availability())
 
 		assert.Equal(t, len(eventDispatcher.events), 2)
 		assert.IsType(t, PlaylistItemAdded{}, eventDispatcher.events[1])
 
 		anotherPlaylistOwner := PlaylistOwnerID(uuid.New())
-		_, err = playlistService.AddToPlaylist(playlistID, anotherPlaylistOwner, content)
-		assert.EqualError(t, err, ErrOnlyOwnerCanManagePlaylist.Error())
+		_, err = playlistService.AddToPlaylist(playlistID, UserID(anotherPlaylistOwner), content, PlaylistItemAvailable)
+		assert.True(t, errors.Is(err, ErrAccessDenied))
 		assert.Equal(t, len(eventDispatcher.events), 2)
 	}
 
 	{
 		playlistRepo = newMockPlaylistRepo()
-		playlistService = NewPlaylistService(playlistRepo, eventDispatcher)
+		playlistService = NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
 
 		playlistName := playlistName
 		playlistOwner := PlaylistOwnerID(uuid.New())
@@ -135,13 +195,13 @@ func TestPlaylistService_AddToPlaylist(t *testing.T) {
 		playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
 		assert.NoError(t, err)
 
-		playlistItemID1, err := playlistService.AddToPlaylist(playlistID, playlistOwner, content1)
+		playlistItemID1, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content1, PlaylistItemAvailable)
 		assert.NoError(t, err)
 
-		playlistItemID2, err := playlistService.AddToPlaylist(playlistID, playlistOwner, content2)
+		playlistItemID2, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content2, PlaylistItemAvailable)
 		assert.NoError(t, err)
 
-		playlistItemID3, err := playlistService.AddToPlaylist(playlistID, playlistOwner, content3)
+		playlistItemID3, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content3, PlaylistItemAvailable)
 		assert.NoError(t, err)
 
 		playlist, ok := playlistRepo.playlists[playlistID]
@@ -166,7 +226,7 @@ func TestPlaylistService_RemoveFromPlaylist(t *testing.T) {
 	playlistRepo := newMockPlaylistRepo()
 	eventDispatcher := newMockEventDispatcher()
 
-	playlistService := NewPlaylistService(playlistRepo, eventDispatcher)
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
 
 	{
 		playlistName := playlistName
@@ -177,13 +237,13 @@ func TestPlaylistService_RemoveFromPlaylist(t *testing.T) {
 		playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
 		assert.NoError(t, err)
 
-		playlistItemID, err := playlistService.AddToPlaylist(playlistID, playlistOwner, content)
+		playlistItemID, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content, PlaylistItemAvailable)
 		assert.NoError(t, err)
 
-		err = playlistService.RemoveFromPlaylist(playlistItemID, anotherPlaylistOwner)
-		assert.EqualError(t, err, ErrOnlyOwnerCanManagePlaylist.Error())
+		err = playlistService.RemoveFromPlaylist(playlistItemID, UserID(anotherPlaylistOwner))
+		assert.True(t, errors.Is(err, ErrAccessDenied))
 
-		err = playlistService.RemoveFromPlaylist(playlistItemID, playlistOwner)
+		err = playlistService.RemoveFromPlaylist(playlistItemID, UserID(playlistOwner))
 		assert.NoError(t, err)
 
 		assert.Equal(t, len(eventDispatcher.events), 3)
@@ -195,7 +255,7 @@ func TestPlaylistService_RemovePlaylist(t *testing.T) {
 	playlistRepo := newMockPlaylistRepo()
 	eventDispatcher := newMockEventDispatcher()
 
-	playlistService := NewPlaylistService(playlistRepo, eventDispatcher)
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
 
 	{
 		playlistName := playlistName
@@ -205,12 +265,12 @@ func TestPlaylistService_RemovePlaylist(t *testing.T) {
 		playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
 		assert.NoError(t, err)
 
-		err = playlistService.RemovePlaylist(playlistID, anotherPlaylistOwner)
-		assert.EqualError(t, err, ErrOnlyOwnerCanManagePlaylist.Error())
+		err = playlistService.RemovePlaylist(playlistID, UserID(anotherPlaylistOwner))
+		assert.True(t, errors.Is(err, ErrAccessDenied))
 
 		assert.Equal(t, len(eventDispatcher.events), 1)
 
-		err = playlistService.RemovePlaylist(playlistID, playlistOwner)
+		err = playlistService.RemovePlaylist(playlistID, UserID(playlistOwner))
 		assert.NoError(t, err)
 
 		assert.Equal(t, len(eventDispatcher.events), 2)
@@ -218,6 +278,144 @@ func TestPlaylistService_RemovePlaylist(t *testing.T) {
 	}
 }
 
+func TestPlaylistService_RemoveOwnerPlaylists(t *testing.T) {
+	playlistRepo := newMockPlaylistRepo()
+	eventDispatcher := newMockEventDispatcher()
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
+
+	playlistOwner := PlaylistOwnerID(uuid.New())
+	anotherPlaylistOwner := PlaylistOwnerID(uuid.New())
+
+	playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
+	assert.NoError(t, err)
+
+	anotherPlaylistID, err := playlistService.CreatePlaylist(playlistName, anotherPlaylistOwner)
+	assert.NoError(t, err)
+
+	eventDispatcher.events = nil
+
+	removed, err := playlistService.RemoveOwnerPlaylists(playlistOwner, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(removed))
+	assert.Equal(t, playlistID, removed[0].Before.ID())
+	assert.Nil(t, removed[0].After)
+
+	_, ok := playlistRepo.playlists[playlistID]
+	assert.False(t, ok)
+	_, ok = playlistRepo.playlists[anotherPlaylistID]
+	assert.True(t, ok)
+
+	assert.Equal(t, []Event{PlaylistRemoved{PlaylistID: playlistID, OwnerID: playlistOwner}}, eventDispatcher.events)
+
+	removed, err = playlistService.RemoveOwnerPlaylists(playlistOwner, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(removed))
+	assert.Equal(t, 1, len(eventDispatcher.events))
+}
+
+func TestPlaylistService_RemoveContent(t *testing.T) {
+	playlistRepo := newMockPlaylistRepo()
+	eventDispatcher := newMockEventDispatcher()
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
+
+	playlistOwner := PlaylistOwnerID(uuid.New())
+	content := ContentID(uuid.New())
+	anotherContent := ContentID(uuid.New())
+
+	playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
+	assert.NoError(t, err)
+
+	playlistItemID1, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content, PlaylistItemAvailable)
+	assert.NoError(t, err)
+
+	playlistItemID2, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content, PlaylistItemUnavailable)
+	assert.NoError(t, err)
+
+	anotherPlaylistItemID, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), anotherContent, PlaylistItemAvailable)
+	assert.NoError(t, err)
+
+	eventDispatcher.events = nil
+
+	changed, err := playlistService.RemoveContent([]ContentID{content}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changed))
+	assert.Equal(t, 3, len(changed[0].Before.Items()), "state before change doesn't share items with changed playlist")
+	assert.Equal(t, 1, len(changed[0].After.Items()))
+
+	playlist := playlistRepo.playlists[playlistID]
+	assert.Equal(t, 1, len(playlist.Items()))
+	_, ok := playlist.Items()[anotherPlaylistItemID]
+	assert.True(t, ok)
+
+	assert.ElementsMatch(t, []Event{
+		PlaylistItemRemoved{PlaylistID: playlistID, PlaylistItemID: playlistItemID1},
+		PlaylistItemRemoved{PlaylistID: playlistID, PlaylistItemID: playlistItemID2},
+	}, eventDispatcher.events)
+
+	changed, err = playlistService.RemoveContent([]ContentID{content}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changed))
+}
+
+func TestPlaylistService_SetContentAvailability(t *testing.T) {
+	playlistRepo := newMockPlaylistRepo()
+	eventDispatcher := newMockEventDispatcher()
+	playlistService := NewPlaylistService(playlistRepo, eventDispatcher, newAuthorizationPolicy())
+
+	playlistOwner := PlaylistOwnerID(uuid.New())
+	content := ContentID(uuid.New())
+	anotherContent := ContentID(uuid.New())
+
+	playlistID, err := playlistService.CreatePlaylist(playlistName, playlistOwner)
+	assert.NoError(t, err)
+
+	playlistItemID, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), content, PlaylistItemAvailable)
+	assert.NoError(t, err)
+
+	anotherPlaylistItemID, err := playlistService.AddToPlaylist(playlistID, UserID(playlistOwner), anotherContent, PlaylistItemAvailable)
+	assert.NoError(t, err)
+
+	eventDispatcher.events = nil
+
+	changed, err := playlistService.SetContentAvailability([]ContentID{content}, PlaylistItemUnavailable, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changed))
+	beforeItem := changed[0].Before.Items()[playlistItemID]
+	assert.Equal(t, PlaylistItemAvailable, beforeItem.Availability())
+
+	playlist := playlistRepo.playlists[playlistID]
+	playlistItem := playlist.Items()[playlistItemID]
+	assert.Equal(t, PlaylistItemUnavailable, playlistItem.Availability())
+	anotherPlaylistItem := playlist.Items()[anotherPlaylistItemID]
+	assert.Equal(t, PlaylistItemAvailable, anotherPlaylistItem.Availability())
+
+	assert.Equal(t, 1, len(eventDispatcher.events))
+	assert.Equal(t, PlaylistItemAvailabilityChanged{
+		PlaylistID:     playlistID,
+		PlaylistItemID: playlistItemID,
+		ContentID:      content,
+		Availability:   PlaylistItemUnavailable,
+	}, eventDispatcher.events[0])
+
+	changed, err = playlistService.SetContentAvailability([]ContentID{content}, PlaylistItemUnavailable, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changed))
+	assert.Equal(t, 1, len(eventDispatcher.events))
+
+	changed, err = playlistService.SetContentAvailability([]ContentID{content}, PlaylistItemAvailable, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changed))
+
+	playlist = playlistRepo.playlists[playlistID]
+	playlistItem = playlist.Items()[playlistItemID]
+	assert.Equal(t, PlaylistItemAvailable, playlistItem.Availability())
+	assert.Equal(t, 2, len(eventDispatcher.events))
+}
+
+func newAuthorizationPolicy() AuthorizationPolicy {
+	return NewRuleBasedAuthorizationPolicy(DefaultAuthorizationRules())
+}
+
 func newMockPlaylistRepo() *mockPlaylistRepository {
 	return &mockPlaylistRepository{
 		map[PlaylistID]Playlist{},
@@ -256,6 +454,49 @@ func (m *mockPlaylistRepository) FindByItemID(playlistItemID PlaylistItemID) (Pl
 	return Playlist{}, ErrPlaylistNotFound
 }
 
+func (m *mockPlaylistRepository) FindAll(spec PlaylistSpecification) ([]Playlist, error) {
+	var result []Playlist
+	for _, playlist := range m.playlists {
+		if spec.Limit > 0 && len(result) == spec.Limit {
+			break
+		}
+		if playlistMatches(playlist, spec) {
+			result = append(result, playlist)
+		}
+	}
+	return result, nil
+}
+
+func playlistMatches(playlist Playlist, spec PlaylistSpecification) bool {
+	if len(spec.OwnerIDs) != 0 && !ownerMatches(playlist, spec) {
+		return false
+	}
+	if len(spec.ContentIDs) == 0 {
+		return true
+	}
+
+	for _, item := range playlist.Items() {
+		if spec.ExceptAvailability != nil && item.Availability() == *spec.ExceptAvailability {
+			continue
+		}
+		for _, contentID := range spec.ContentIDs {
+			if item.ContentID() == contentID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ownerMatches(playlist Playlist, spec PlaylistSpecification) bool {
+	for _, ownerID := range spec.OwnerIDs {
+		if playlist.OwnerID() == ownerID {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *mockPlaylistRepository) Store(playlist Playlist) error {
 	m.playlists[playlist.ID()] = playlist
 
@@ -11,22 +11,27 @@ type PlaylistData interface {
 	Items() []PlaylistItemData
 	CreatedAt() *time.Time
 	UpdatedAt() *time.Time
+	Version() int
+	Discoverable() bool
 }
 
 type PlaylistItemData interface {
 	ID() PlaylistItemID
 	ContentID() ContentID
+	Availability() PlaylistItemAvailability
 	CreatedAt() *time.Time
 }
 
 func LoadPlaylist(data PlaylistData) Playlist {
 	return Playlist{
-		id:        data.ID(),
-		name:      data.Name(),
-		ownerID:   data.OwnerID(),
-		items:     mapItems(data.Items()),
-		createdAt: data.CreatedAt(),
-		updatedAt: data.UpdatedAt(),
+		id:           data.ID(),
+		name:         data.Name(),
+		ownerID:      data.OwnerID(),
+		items:        mapItems(data.Items()),
+		createdAt:    data.CreatedAt(),
+		updatedAt:    data.UpdatedAt(),
+		version:      data.Version(),
+		discoverable: data.Discoverable(),
 	}
 }
 
@@ -34,9 +39,10 @@ func mapItems(items []PlaylistItemData) map[PlaylistItemID]PlaylistItem {
 	result := make(map[PlaylistItemID]PlaylistItem)
 	for _, item := range items {
 		result[item.ID()] = PlaylistItem{
-			id:        item.ID(),
-			contentID: item.ContentID(),
-			createdAt: item.CreatedAt(),
+			id:           item.ID(),
+			contentID:    item.ContentID(),
+			availability: item.Availability(),
+			createdAt:    item.CreatedAt(),
 		}
 	}
 	return result
@@ -1,36 +1,55 @@
 package domain
 
-import (
-	"errors"
-)
-
 type PlaylistService interface {
 	CreatePlaylist(name string, ownerID PlaylistOwnerID) (PlaylistID, error)
-	SetPlaylistName(id PlaylistID, ownerID PlaylistOwnerID, newName string) error
-	AddToPlaylist(id PlaylistID, ownerID PlaylistOwnerID, contentID ContentID) (PlaylistItemID, error)
-	RemoveFromPlaylist(id PlaylistItemID, ownerID PlaylistOwnerID) error
-	RemovePlaylist(id PlaylistID, ownerID PlaylistOwnerID) error
+	SetPlaylistName(id PlaylistID, userID UserID, newName string) error
+	SetPlaylistDiscoverable(id PlaylistID, userID UserID, discoverable bool) error
+	AddToPlaylist(id PlaylistID, userID UserID, contentID ContentID, availability PlaylistItemAvailability) (PlaylistItemID, error)
+	RemoveFromPlaylist(id PlaylistItemID, userID UserID) error
+	RemovePlaylist(id PlaylistID, userID UserID) error
+	// RemoveOwnerPlaylists removes at most limit playlists of owner, returns changes of removed playlists
+	RemoveOwnerPlaylists(ownerID PlaylistOwnerID, limit int) ([]PlaylistChange, error)
+	// RemoveContent removes items from at most limit playlists, returns changes of touched playlists
+	RemoveContent(contentIDs []ContentID, limit int) ([]PlaylistChange, error)
+	// SetContentAvailability changes items of at most limit playlists, returns changes of touched playlists
+	SetContentAvailability(contentIDs []ContentID, availability PlaylistItemAvailability, limit int) ([]PlaylistChange, error)
+}
+
+// PlaylistChange holds states of playlist touched by batch change, After is nil for removed playlist
+type PlaylistChange struct {
+	Before Playlist
+	After  *Playlist
 }
 
-func NewPlaylistService(playlistRepo PlaylistRepository, eventDispatcher EventDispatcher) PlaylistService {
+func NewPlaylistService(
+	playlistRepo PlaylistRepository,
+	eventDispatcher EventDispatcher,
+	authorizationPolicy AuthorizationPolicy,
+) PlaylistService {
 	return &playlistService{
-		playlistRepo:    playlistRepo,
-		eventDispatcher: eventDispatcher,
+		playlistRepo:        playlistRepo,
+		eventDispatcher:     eventDispatcher,
+		authorizationPolicy: authorizationPolicy,
 	}
 }
 
-var (
-	ErrOnlyOwnerCanManagePlaylist = errors.New("only owner can manage playlist")
-)
-
 type playlistService struct {
-	playlistRepo    PlaylistRepository
-	eventDispatcher EventDispatcher
+	playlistRepo        PlaylistRepository
+	eventDispatcher     EventDispatcher
+	authorizationPolicy AuthorizationPolicy
 }
 
 func (service *playlistService) CreatePlaylist(name string, ownerID PlaylistOwnerID) (PlaylistID, error) {
 	playlistID := service.playlistRepo.NewID()
 
+	err := service.authorizationPolicy.Authorize(UserID(ownerID), ActionCreatePlaylist, AuthorizationTarget{
+		PlaylistID: playlistID,
+		OwnerID:    ownerID,
+	})
+	if err != nil {
+		return PlaylistID{}, err
+	}
+
 	playlist, err := NewPlaylist(playlistID, name, ownerID)
 	if err != nil {
 		return PlaylistID{}, err
@@ -52,14 +71,15 @@ func (service *playlistService) CreatePlaylist(name string, ownerID PlaylistOwne
 	return playlistID, err
 }
 
-func (service *playlistService) SetPlaylistName(id PlaylistID, ownerID PlaylistOwnerID, newName string) error {
+func (service *playlistService) SetPlaylistName(id PlaylistID, userID UserID, newName string) error {
 	playlist, err := service.playlistRepo.Find(id)
 	if err != nil {
 		return err
 	}
 
-	if playlist.OwnerID() != ownerID {
-		return ErrOnlyOwnerCanManagePlaylist
+	err = service.authorize(userID, ActionSetPlaylistName, playlist)
+	if err != nil {
+		return err
 	}
 
 	if playlist.Name() == newName {
@@ -76,19 +96,50 @@ func (service *playlistService) SetPlaylistName(id PlaylistID, ownerID PlaylistO
 	return service.eventDispatcher.Dispatch(PlaylistNameChanged{PlaylistID: id, NewName: newName})
 }
 
-func (service *playlistService) AddToPlaylist(id PlaylistID, ownerID PlaylistOwnerID, contentID ContentID) (PlaylistItemID, error) {
+func (service *playlistService) SetPlaylistDiscoverable(id PlaylistID, userID UserID, discoverable bool) error {
+	playlist, err := service.playlistRepo.Find(id)
+	if err != nil {
+		return err
+	}
+
+	err = service.authorize(userID, ActionSetPlaylistDiscoverable, playlist)
+	if err != nil {
+		return err
+	}
+
+	if playlist.Discoverable() == discoverable {
+		return nil
+	}
+
+	playlist.SetDiscoverable(discoverable)
+
+	err = service.playlistRepo.Store(playlist)
+	if err != nil {
+		return err
+	}
+
+	return service.eventDispatcher.Dispatch(PlaylistDiscoverabilityChanged{PlaylistID: id, Discoverable: discoverable})
+}
+
+func (service *playlistService) AddToPlaylist(
+	id PlaylistID,
+	userID UserID,
+	contentID ContentID,
+	availability PlaylistItemAvailability,
+) (PlaylistItemID, error) {
 	playlist, err := service.playlistRepo.Find(id)
 	if err != nil {
 		return [16]byte{}, err
 	}
 
-	if playlist.OwnerID() != ownerID {
-		return [16]byte{}, ErrOnlyOwnerCanManagePlaylist
+	err = service.authorize(userID, ActionAddToPlaylist, playlist)
+	if err != nil {
+		return [16]byte{}, err
 	}
 
 	newPlaylistItemID := service.playlistRepo.NewPlaylistItemID()
 
-	playlist.AddItem(newPlaylistItemID, contentID)
+	playlist.AddItem(newPlaylistItemID, contentID, availability)
 
 	err = service.playlistRepo.Store(playlist)
 	if err != nil {
@@ -99,6 +150,7 @@ func (service *playlistService) AddToPlaylist(id PlaylistID, ownerID PlaylistOwn
 		PlaylistID:     playlist.ID(),
 		PlaylistItemID: newPlaylistItemID,
 		ContentID:      contentID,
+		Availability:   availability,
 	})
 	if err != nil {
 		return [16]byte{}, err
@@ -107,14 +159,15 @@ func (service *playlistService) AddToPlaylist(id PlaylistID, ownerID PlaylistOwn
 	return newPlaylistItemID, nil
 }
 
-func (service *playlistService) RemoveFromPlaylist(id PlaylistItemID, ownerID PlaylistOwnerID) error {
+func (service *playlistService) RemoveFromPlaylist(id PlaylistItemID, userID UserID) error {
 	playlist, err := service.playlistRepo.FindByItemID(id)
 	if err != nil {
 		return err
 	}
 
-	if playlist.OwnerID() != ownerID {
-		return ErrOnlyOwnerCanManagePlaylist
+	err = service.authorize(userID, ActionRemoveFromPlaylist, playlist)
+	if err != nil {
+		return err
 	}
 
 	err = playlist.RemoveItem(id)
@@ -133,14 +186,15 @@ func (service *playlistService) RemoveFromPlaylist(id PlaylistItemID, ownerID Pl
 	})
 }
 
-func (service *playlistService) RemovePlaylist(id PlaylistID, ownerID PlaylistOwnerID) error {
+func (service *playlistService) RemovePlaylist(id PlaylistID, userID UserID) error {
 	playlist, err := service.playlistRepo.Find(id)
 	if err != nil {
 		return err
 	}
 
-	if playlist.OwnerID() != ownerID {
-		return ErrOnlyOwnerCanManagePlaylist
+	err = service.authorize(userID, ActionRemovePlaylist, playlist)
+	if err != nil {
+		return err
 	}
 
 	err = service.playlistRepo.Remove(id)
@@ -150,6 +204,133 @@ func (service *playlistService) RemovePlaylist(id PlaylistID, ownerID PlaylistOw
 
 	return service.eventDispatcher.Dispatch(PlaylistRemoved{
 		PlaylistID: id,
-		OwnerID:    ownerID,
+		OwnerID:    playlist.OwnerID(),
+	})
+}
+
+func (service *playlistService) RemoveOwnerPlaylists(ownerID PlaylistOwnerID, limit int) ([]PlaylistChange, error) {
+	playlists, err := service.playlistRepo.FindAll(PlaylistSpecification{
+		OwnerIDs: []PlaylistOwnerID{ownerID},
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]PlaylistChange, 0, len(playlists))
+	for _, playlist := range playlists {
+		err = service.playlistRepo.Remove(playlist.ID())
+		if err != nil {
+			return nil, err
+		}
+
+		err = service.eventDispatcher.Dispatch(PlaylistRemoved{
+			PlaylistID: playlist.ID(),
+			OwnerID:    playlist.OwnerID(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, PlaylistChange{Before: playlist})
+	}
+
+	return changes, nil
+}
+
+func (service *playlistService) RemoveContent(contentIDs []ContentID, limit int) ([]PlaylistChange, error) {
+	playlists, err := service.playlistRepo.FindAll(PlaylistSpecification{
+		ContentIDs: contentIDs,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]PlaylistChange, 0, len(playlists))
+	for _, playlist := range playlists {
+		before := playlist.Clone()
+		var events []Event
+		for _, contentID := range contentIDs {
+			for _, itemID := range playlist.RemoveContent(contentID) {
+				events = append(events, PlaylistItemRemoved{
+					PlaylistID:     playlist.ID(),
+					PlaylistItemID: itemID,
+				})
+			}
+		}
+
+		err = service.playlistRepo.Store(playlist)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			err = service.eventDispatcher.Dispatch(event)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		after := playlist
+		changes = append(changes, PlaylistChange{Before: before, After: &after})
+	}
+
+	return changes, nil
+}
+
+func (service *playlistService) SetContentAvailability(
+	contentIDs []ContentID,
+	availability PlaylistItemAvailability,
+	limit int,
+) ([]PlaylistChange, error) {
+	playlists, err := service.playlistRepo.FindAll(PlaylistSpecification{
+		ContentIDs:         contentIDs,
+		ExceptAvailability: &availability,
+		Limit:              limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]PlaylistChange, 0, len(playlists))
+	for _, playlist := range playlists {
+		before := playlist.Clone()
+		var events []Event
+		for _, contentID := range contentIDs {
+			for _, itemID := range playlist.SetContentAvailability(contentID, availability) {
+				events = append(events, PlaylistItemAvailabilityChanged{
+					PlaylistID:     playlist.ID(),
+					PlaylistItemID: itemID,
+					ContentID:      contentID,
+					Availability:   availability,
+				})
+			}
+		}
+
+		err = service.playlistRepo.Store(playlist)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			err = service.eventDispatcher.Dispatch(event)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		after := playlist
+		changes = append(changes, PlaylistChange{Before: before, After: &after})
+	}
+
+	return changes, nil
+}
+
+func (service *playlistService) authorize(userID UserID, action Action, playlist Playlist) error {
+	return service.authorizationPolicy.Authorize(userID, action, AuthorizationTarget{
+		PlaylistID:   playlist.ID(),
+		OwnerID:      playlist.OwnerID(),
+		Discoverable: playlist.Discoverable(),
 	})
 }
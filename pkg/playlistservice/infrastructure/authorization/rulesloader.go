@@ -0,0 +1,64 @@
+package authorization
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+// LoadRules reads authorization rules from json file and merges them over default rules,
+// permissions of roles mentioned in file replace default ones
+func LoadRules(path string) (domain.AuthorizationRules, error) {
+	rules := domain.DefaultAuthorizationRules()
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return domain.AuthorizationRules{}, errors.Wrap(err, "failed to read authorization rules")
+	}
+
+	var fileRules jsonRules
+	err = json.Unmarshal(data, &fileRules)
+	if err != nil {
+		return domain.AuthorizationRules{}, errors.Wrap(err, "failed to parse authorization rules")
+	}
+
+	for role, actions := range fileRules.Permissions {
+		permissions := make([]domain.Action, 0, len(actions))
+		for _, action := range actions {
+			permissions = append(permissions, domain.Action(action))
+		}
+		rules.Permissions[domain.Role(role)] = permissions
+	}
+
+	for _, assignment := range fileRules.Assignments {
+		roleAssignment := domain.RoleAssignment{
+			UserID: domain.UserID(assignment.UserID),
+			Role:   domain.Role(assignment.Role),
+		}
+		if assignment.PlaylistID != nil {
+			playlistID := domain.PlaylistID(*assignment.PlaylistID)
+			roleAssignment.PlaylistID = &playlistID
+		}
+		rules.Assignments = append(rules.Assignments, roleAssignment)
+	}
+
+	return rules, nil
+}
+
+type jsonRules struct {
+	Permissions map[string][]string  `json:"permissions"`
+	Assignments []jsonRoleAssignment `json:"assignments"`
+}
+
+type jsonRoleAssignment struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Role       string     `json:"role"`
+	PlaylistID *uuid.UUID `json:"playlist_id"`
+}
@@ -1,6 +1,10 @@
 package infrastructure
 
 import (
+	"time"
+
+	"github.com/google/uuid"
+
 	commonauth "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
 	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
 	commonstoredevent "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/storedevent"
@@ -14,15 +18,45 @@ import (
 	"playlistservice/pkg/playlistservice/infrastructure/integrationevent"
 	"playlistservice/pkg/playlistservice/infrastructure/mysql"
 	mysqlquery "playlistservice/pkg/playlistservice/infrastructure/mysql/query"
+	"playlistservice/pkg/playlistservice/infrastructure/mysql/repository"
 	infrastuctureservice "playlistservice/pkg/playlistservice/infrastructure/mysql/service"
 	infrastructureservice "playlistservice/pkg/playlistservice/infrastructure/service"
 )
 
+type Config struct {
+	IdempotencyKeyTTL                   time.Duration
+	IdempotencyRecordPurgeBatchSize     int
+	AuthorizationRules                  domain.AuthorizationRules
+	ContentCache                        infrastructureservice.ContentCacheConfig
+	ContentService                      infrastructureservice.ResilienceConfig
+	ContentCheckFallback                service.ContentCheckFallback
+	PendingContentVerificationBatchSize int
+	ContentReconciliationBatchSize      int
+	DataExportBatchSize                 int
+	Trending                            service.TrendingConfig
+}
+
 type DependencyContainer interface {
 	PlaylistService() service.PlaylistService
 	PlaylistQueryService() query.PlaylistQueryService
+	PlaylistReadModelQueryService() query.PlaylistQueryService
+	PlaylistSearchService() query.PlaylistSearchService
+	PlaylistContentQueryService() query.PlaylistContentQueryService
+	AuditLogQueryService() query.AuditLogQueryService
+	ContentQueryService() query.ContentQueryService
+	AuthorizationPolicy() domain.AuthorizationPolicy
+	ContentCache() service.ContentCache
+	ContentCacheStats() infrastructureservice.ContentCacheStats
+	PendingContentVerifier() service.PendingContentVerifier
+	ContentReconciler() service.ContentReconciler
+	DataExportService() service.DataExportService
+	IdempotencyRecordPurger() service.IdempotencyRecordPurger
+	TrendingPlaylistQueryService() query.TrendingPlaylistQueryService
+	PlaylistReadCounter() service.PlaylistReadCounter
+	TrendingRanker() service.TrendingRanker
 	UserDescriptorSerializer() commonauth.UserDescriptorSerializer
 	IntegrationEventHandler() integrationevent.Handler
+	ContentCacheInvalidationHandler() integrationevent.Handler
 }
 
 func NewDependencyContainer(
@@ -31,21 +65,72 @@ func NewDependencyContainer(
 	contentServiceClient contentserviceapi.ContentServiceClient,
 	eventStore commonstoredevent.Store,
 	storedEventSenderCallback mysql.UnitOfWorkCompleteNotifier,
+	config Config,
 ) DependencyContainer {
 	unitOfWorkFactory, completeNotifier := unitOfWorkFactory(client)
 
 	completeNotifier.subscribe(storedEventSenderCallback)
 
+	dispatcher := eventDispatcher(eventStore)
+	policy := authorizationPolicy(config.AuthorizationRules)
+	resilientContentServiceClient := infrastructureservice.NewResilientContentServiceClient(contentServiceClient, config.ContentService)
+	checker, cache := contentChecker(resilientContentServiceClient, config.ContentCache)
+
+	appPlaylistService := playlistService(
+		client,
+		checker,
+		unitOfWorkFactory,
+		dispatcher,
+		policy,
+		config.ContentCheckFallback,
+		config.IdempotencyKeyTTL,
+	)
+
 	container := &dependencyContainer{
-		playlistService: playlistService(
-			contentChecker(contentServiceClient),
-			unitOfWorkFactory,
-			eventDispatcher(eventStore),
-			client,
-		),
-		playlistQueryService:     playlistQueryService(client),
-		userDescriptorSerializer: userDescriptorSerializer(),
+		playlistService:             appPlaylistService,
+		pendingContentVerifier:      pendingContentVerifier(checker, client, appPlaylistService, config.PendingContentVerificationBatchSize),
+		playlistQueryService:        playlistQueryService(client),
+		playlistSearchService:       playlistSearchService(client),
+		playlistContentQueryService: playlistContentQueryService(client),
+		auditLogQueryService:        auditLogQueryService(client),
+		contentQueryService:         contentQueryService(resilientContentServiceClient),
+		authorizationPolicy:         policy,
+		contentCache:                cache,
+		userDescriptorSerializer:    userDescriptorSerializer(),
 	}
+	container.playlistReadModelQueryService = playlistReadModelQueryService(client)
+	container.idempotencyRecordPurger = service.NewIdempotencyRecordPurger(
+		repository.NewIdempotencyRecordRepository(client),
+		config.IdempotencyKeyTTL,
+		config.IdempotencyRecordPurgeBatchSize,
+	)
+
+	container.dataExportService = dataExportService(
+		unitOfWorkFactory,
+		container.PlaylistQueryService(),
+		client,
+		dispatcher,
+		config.DataExportBatchSize,
+	)
+
+	// reconciler bypasses cache, its purpose is to catch content changes which events were lost
+	container.contentReconciler = contentReconciler(
+		infrastructureservice.NewContentChecker(resilientContentServiceClient),
+		container.ContentCache(),
+		client,
+		appPlaylistService,
+		config.ContentReconciliationBatchSize,
+	)
+
+	trendingStorage := infrastuctureservice.NewTrendingPlaylistStorage(client)
+	container.trendingPlaylistQueryService = trendingPlaylistQueryService(client)
+	container.playlistReadCounter = service.NewPlaylistReadCounter(trendingStorage)
+	container.trendingRanker = service.NewTrendingRanker(
+		trendingStorage,
+		container.ContentQueryService(),
+		mysql.NewTrendingRankingLock(client),
+		config.Trending,
+	)
 
 	container.integrationEventHandler = integrationEventHandler(logger, container)
 
@@ -67,10 +152,24 @@ func (notifier *completeNotifier) onComplete() {
 }
 
 type dependencyContainer struct {
-	playlistService          service.PlaylistService
-	playlistQueryService     query.PlaylistQueryService
-	userDescriptorSerializer commonauth.UserDescriptorSerializer
-	integrationEventHandler  integrationevent.Handler
+	playlistService               service.PlaylistService
+	playlistQueryService          query.PlaylistQueryService
+	playlistReadModelQueryService query.PlaylistQueryService
+	playlistSearchService         query.PlaylistSearchService
+	playlistContentQueryService   query.PlaylistContentQueryService
+	auditLogQueryService          query.AuditLogQueryService
+	contentQueryService           query.ContentQueryService
+	authorizationPolicy           domain.AuthorizationPolicy
+	contentCache                  infrastructureservice.CachedContentChecker
+	pendingContentVerifier        service.PendingContentVerifier
+	contentReconciler             service.ContentReconciler
+	dataExportService             service.DataExportService
+	idempotencyRecordPurger       service.IdempotencyRecordPurger
+	trendingPlaylistQueryService  query.TrendingPlaylistQueryService
+	playlistReadCounter           service.PlaylistReadCounter
+	trendingRanker                service.TrendingRanker
+	userDescriptorSerializer      commonauth.UserDescriptorSerializer
+	integrationEventHandler       integrationevent.Handler
 }
 
 func (container *dependencyContainer) PlaylistService() service.PlaylistService {
@@ -81,6 +180,72 @@ func (container *dependencyContainer) PlaylistQueryService() query.PlaylistQuery
 	return container.playlistQueryService
 }
 
+func (container *dependencyContainer) PlaylistReadModelQueryService() query.PlaylistQueryService {
+	return container.playlistReadModelQueryService
+}
+
+func (container *dependencyContainer) PlaylistSearchService() query.PlaylistSearchService {
+	return container.playlistSearchService
+}
+
+func (container *dependencyContainer) PlaylistContentQueryService() query.PlaylistContentQueryService {
+	return container.playlistContentQueryService
+}
+
+func (container *dependencyContainer) AuditLogQueryService() query.AuditLogQueryService {
+	return container.auditLogQueryService
+}
+
+func (container *dependencyContainer) ContentQueryService() query.ContentQueryService {
+	return container.contentQueryService
+}
+
+func (container *dependencyContainer) AuthorizationPolicy() domain.AuthorizationPolicy {
+	return container.authorizationPolicy
+}
+
+func (container *dependencyContainer) ContentCache() service.ContentCache {
+	if container.contentCache == nil {
+		return noopContentCache{}
+	}
+	return container.contentCache
+}
+
+func (container *dependencyContainer) ContentCacheStats() infrastructureservice.ContentCacheStats {
+	if container.contentCache == nil {
+		return infrastructureservice.ContentCacheStats{}
+	}
+	return container.contentCache.Stats()
+}
+
+func (container *dependencyContainer) PendingContentVerifier() service.PendingContentVerifier {
+	return container.pendingContentVerifier
+}
+
+func (container *dependencyContainer) ContentReconciler() service.ContentReconciler {
+	return container.contentReconciler
+}
+
+func (container *dependencyContainer) DataExportService() service.DataExportService {
+	return container.dataExportService
+}
+
+func (container *dependencyContainer) IdempotencyRecordPurger() service.IdempotencyRecordPurger {
+	return container.idempotencyRecordPurger
+}
+
+func (container *dependencyContainer) TrendingPlaylistQueryService() query.TrendingPlaylistQueryService {
+	return container.trendingPlaylistQueryService
+}
+
+func (container *dependencyContainer) PlaylistReadCounter() service.PlaylistReadCounter {
+	return container.playlistReadCounter
+}
+
+func (container *dependencyContainer) TrendingRanker() service.TrendingRanker {
+	return container.trendingRanker
+}
+
 func (container *dependencyContainer) UserDescriptorSerializer() commonauth.UserDescriptorSerializer {
 	return container.userDescriptorSerializer
 }
@@ -89,6 +254,10 @@ func (container *dependencyContainer) IntegrationEventHandler() integrationevent
 	return container.integrationEventHandler
 }
 
+func (container *dependencyContainer) ContentCacheInvalidationHandler() integrationevent.Handler {
+	return integrationevent.NewContentCacheInvalidationHandler(container.ContentCache())
+}
+
 func unitOfWorkFactory(client commonmysql.TransactionalClient) (service.UnitOfWorkFactory, *completeNotifier) {
 	notifier := &completeNotifier{}
 
@@ -112,31 +281,128 @@ func eventDispatcher(store commonstoredevent.Store) domain.EventDispatcher {
 }
 
 func playlistService(
+	client commonmysql.Client,
 	contentChecker service.ContentChecker,
 	unitOfWork service.UnitOfWorkFactory,
 	eventDispatcher domain.EventDispatcher,
-	client commonmysql.Client,
+	policy domain.AuthorizationPolicy,
+	contentCheckFallback service.ContentCheckFallback,
+	idempotencyKeyTTL time.Duration,
 ) service.PlaylistService {
 	return service.NewPlaylistService(
 		contentChecker,
+		eventDispatcher,
+		policy,
+		contentCheckFallback,
+		service.NewIdempotentResultFinder(repository.NewIdempotencyRecordRepository(client), idempotencyKeyTTL),
+		service.NewUnitOfWorkMiddleware(unitOfWork),
+		service.NewIdempotencyMiddleware(idempotencyKeyTTL),
+		service.NewPlaylistVersionMiddleware(),
+		service.NewAuditMiddleware(),
+	)
+}
+
+func pendingContentVerifier(
+	contentChecker service.ContentChecker,
+	client commonmysql.TransactionalClient,
+	playlistService service.PlaylistService,
+	batchSize int,
+) service.PendingContentVerifier {
+	return service.NewPendingContentVerifier(
+		contentChecker,
+		infrastuctureservice.NewPlaylistContentStorage(client),
+		mysql.NewPendingContentVerificationLock(client),
+		playlistService,
+		batchSize,
+	)
+}
+
+func contentReconciler(
+	contentChecker service.ContentChecker,
+	cache service.ContentCache,
+	client commonmysql.TransactionalClient,
+	playlistService service.PlaylistService,
+	batchSize int,
+) service.ContentReconciler {
+	return service.NewContentReconciler(
+		contentChecker,
+		cache,
+		infrastuctureservice.NewPlaylistContentStorage(client),
+		mysql.NewContentReconciliationTracker(client),
+		playlistService,
+		batchSize,
+	)
+}
+
+func dataExportService(
+	unitOfWork service.UnitOfWorkFactory,
+	playlistQueryService query.PlaylistQueryService,
+	client commonmysql.Client,
+	eventDispatcher domain.EventDispatcher,
+	batchSize int,
+) service.DataExportService {
+	return service.NewDataExportService(
 		unitOfWork,
+		playlistQueryService,
+		mysqlquery.NewStoredEventQueryService(client),
 		eventDispatcher,
-		infrastuctureservice.NewPlaylistRemover(client),
+		batchSize,
 	)
 }
 
+func authorizationPolicy(rules domain.AuthorizationRules) domain.AuthorizationPolicy {
+	return domain.NewRuleBasedAuthorizationPolicy(rules)
+}
+
 func playlistQueryService(client commonmysql.TransactionalClient) query.PlaylistQueryService {
 	return mysqlquery.NewPlaylistQueryService(client)
 }
 
+func playlistReadModelQueryService(client commonmysql.TransactionalClient) query.PlaylistQueryService {
+	return mysqlquery.NewPlaylistReadModelQueryService(client)
+}
+
+func playlistSearchService(client commonmysql.TransactionalClient) query.PlaylistSearchService {
+	return mysqlquery.NewPlaylistSearchService(client)
+}
+
+func playlistContentQueryService(client commonmysql.TransactionalClient) query.PlaylistContentQueryService {
+	return mysqlquery.NewPlaylistContentQueryService(client)
+}
+
+func auditLogQueryService(client commonmysql.TransactionalClient) query.AuditLogQueryService {
+	return mysqlquery.NewAuditLogQueryService(client)
+}
+
+func trendingPlaylistQueryService(client commonmysql.TransactionalClient) query.TrendingPlaylistQueryService {
+	return mysqlquery.NewTrendingPlaylistQueryService(client)
+}
+
+func contentQueryService(contentServiceClient contentserviceapi.ContentServiceClient) query.ContentQueryService {
+	return infrastructureservice.NewContentQueryService(contentServiceClient)
+}
+
 func userDescriptorSerializer() commonauth.UserDescriptorSerializer {
 	return commonauth.NewUserDescriptorSerializer()
 }
 
-func contentChecker(contentServiceClient contentserviceapi.ContentServiceClient) service.ContentChecker {
-	return infrastructureservice.NewContentChecker(contentServiceClient)
+func contentChecker(
+	contentServiceClient contentserviceapi.ContentServiceClient,
+	cacheConfig infrastructureservice.ContentCacheConfig,
+) (service.ContentChecker, infrastructureservice.CachedContentChecker) {
+	checker := infrastructureservice.NewContentChecker(contentServiceClient)
+	if !cacheConfig.Enabled {
+		return checker, nil
+	}
+
+	cachedChecker := infrastructureservice.NewCachedContentChecker(checker, cacheConfig)
+	return cachedChecker, cachedChecker
 }
 
+type noopContentCache struct{}
+
+func (cache noopContentCache) Invalidate([]uuid.UUID) {}
+
 func integrationEventHandler(logger log.Logger, container DependencyContainer) integrationevent.Handler {
 	return integrationevent.NewIntegrationEventHandler(logger, container)
 }
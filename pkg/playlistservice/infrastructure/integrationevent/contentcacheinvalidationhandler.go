@@ -0,0 +1,47 @@
+package integrationevent
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+// NewContentCacheInvalidationHandler invalidates local content cache of instance, so it must receive events on every instance
+func NewContentCacheInvalidationHandler(cache service.ContentCache) Handler {
+	return &contentCacheInvalidationHandler{cache: cache}
+}
+
+type contentCacheInvalidationHandler struct {
+	cache service.ContentCache
+}
+
+func (handler *contentCacheInvalidationHandler) Handle(msgBody string) error {
+	var e event
+
+	err := json.Unmarshal([]byte(msgBody), &e)
+	if err != nil {
+		return err
+	}
+
+	if e.Type != "content_availability_type_changed" && e.Type != "content_deleted" {
+		return nil
+	}
+
+	// both payloads identify content by content_id
+	payload := contentDeletedPayload{}
+	err = json.Unmarshal(e.Payload, &payload)
+	if err != nil {
+		return err
+	}
+
+	contentID, err := uuid.Parse(payload.ContentID)
+	if err != nil {
+		return err
+	}
+
+	handler.cache.Invalidate([]uuid.UUID{contentID})
+
+	return nil
+}
@@ -58,16 +58,14 @@ func (handler *integrationEventHandler) handleEvents(e event) error {
 			return err
 		}
 
-		if payload.ContentAvailabilityType != privateContentAvailabilityType {
-			return nil
-		}
-
 		contentID, err := uuid.Parse(payload.ContentID)
 		if err != nil {
 			return err
 		}
 
-		return handler.container.PlaylistService().RemoveFromPlaylists([]uuid.UUID{contentID})
+		available := payload.ContentAvailabilityType != privateContentAvailabilityType
+
+		return handler.container.PlaylistService().SetContentAvailability([]uuid.UUID{contentID}, available)
 	}
 
 	if e.Type == "content_deleted" {
@@ -85,6 +83,21 @@ func (handler *integrationEventHandler) handleEvents(e event) error {
 		return handler.container.PlaylistService().RemoveFromPlaylists([]uuid.UUID{contentID})
 	}
 
+	if e.Type == "user_deleted" {
+		payload := userDeletedPayload{}
+		err := json.Unmarshal(e.Payload, &payload)
+		if err != nil {
+			return err
+		}
+
+		userID, err := uuid.Parse(payload.UserID)
+		if err != nil {
+			return err
+		}
+
+		return handler.container.PlaylistService().RemoveUserPlaylists(userID)
+	}
+
 	return nil
 }
 
@@ -101,3 +114,7 @@ type contentAvailabilityTypeChangedPayload struct {
 type contentDeletedPayload struct {
 	ContentID string `json:"content_id"`
 }
+
+type userDeletedPayload struct {
+	UserID string `json:"user_id"`
+}
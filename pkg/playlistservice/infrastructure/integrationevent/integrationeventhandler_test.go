@@ -0,0 +1,165 @@
+package integrationevent
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+
+	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/app/service"
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+func TestIntegrationEventHandler_UserDeleted(t *testing.T) {
+	deletedUserID, anotherUserID := uuid.New(), uuid.New()
+
+	repo := &mockPlaylistRepository{playlists: map[domain.PlaylistID]domain.Playlist{}}
+	// more playlists than fit in single batch
+	for i := 0; i < 150; i++ {
+		repo.add(t, deletedUserID)
+	}
+	anotherPlaylistID := repo.add(t, anotherUserID)
+
+	dispatcher := &mockEventDispatcher{}
+	playlistService := service.NewPlaylistService(
+		nil,
+		dispatcher,
+		nil,
+		service.ContentCheckFallbackReject,
+		nil,
+		service.NewUnitOfWorkMiddleware(&mockUnitOfWorkFactory{repo: repo}),
+	)
+	handler := NewIntegrationEventHandler(&mockLogger{}, &mockDependencyContainer{playlistService: playlistService})
+
+	msgBody := fmt.Sprintf(`{"type": "user_deleted", "payload": {"user_id": "%s"}}`, deletedUserID)
+
+	{
+		assert.NoError(t, handler.Handle(msgBody))
+		assert.Len(t, dispatcher.events, 150)
+		assert.Len(t, repo.playlists, 1)
+		assert.Contains(t, repo.playlists, anotherPlaylistID, "playlists of other users are kept")
+	}
+
+	{
+		assert.NoError(t, handler.Handle(msgBody), "redelivered event is handled again")
+		assert.Len(t, dispatcher.events, 150, "nothing is removed twice")
+		assert.Len(t, repo.playlists, 1)
+	}
+}
+
+type mockDependencyContainer struct {
+	playlistService service.PlaylistService
+}
+
+func (container *mockDependencyContainer) PlaylistService() service.PlaylistService {
+	return container.playlistService
+}
+
+type mockUnitOfWorkFactory struct {
+	repo domain.PlaylistRepository
+}
+
+func (factory *mockUnitOfWorkFactory) NewUnitOfWork(string) (service.UnitOfWork, error) {
+	return &mockUnitOfWork{repo: factory.repo}, nil
+}
+
+type mockUnitOfWork struct {
+	service.RepositoryProvider
+	repo domain.PlaylistRepository
+}
+
+func (unitOfWork *mockUnitOfWork) PlaylistRepository() domain.PlaylistRepository {
+	return unitOfWork.repo
+}
+
+func (unitOfWork *mockUnitOfWork) Complete(err error) error {
+	return err
+}
+
+type mockPlaylistRepository struct {
+	playlists map[domain.PlaylistID]domain.Playlist
+}
+
+func (repo *mockPlaylistRepository) add(t *testing.T, ownerID uuid.UUID) domain.PlaylistID {
+	playlist, err := domain.NewPlaylist(repo.NewID(), "playlist", domain.PlaylistOwnerID(ownerID))
+	assert.NoError(t, err)
+	repo.playlists[playlist.ID()] = playlist
+	return playlist.ID()
+}
+
+func (repo *mockPlaylistRepository) NewID() domain.PlaylistID {
+	return domain.PlaylistID(uuid.New())
+}
+
+func (repo *mockPlaylistRepository) NewPlaylistItemID() domain.PlaylistItemID {
+	return domain.PlaylistItemID(uuid.New())
+}
+
+func (repo *mockPlaylistRepository) Find(id domain.PlaylistID) (domain.Playlist, error) {
+	playlist, ok := repo.playlists[id]
+	if !ok {
+		return domain.Playlist{}, domain.ErrPlaylistNotFound
+	}
+	return playlist, nil
+}
+
+func (repo *mockPlaylistRepository) FindByItemID(domain.PlaylistItemID) (domain.Playlist, error) {
+	return domain.Playlist{}, domain.ErrPlaylistByItemNotFound
+}
+
+func (repo *mockPlaylistRepository) FindAll(spec domain.PlaylistSpecification) ([]domain.Playlist, error) {
+	var result []domain.Playlist
+	for _, playlist := range repo.playlists {
+		for _, ownerID := range spec.OwnerIDs {
+			if playlist.OwnerID() == ownerID {
+				result = append(result, playlist)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].ID(), result[j].ID()
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	if spec.Limit > 0 && len(result) > spec.Limit {
+		result = result[:spec.Limit]
+	}
+	return result, nil
+}
+
+func (repo *mockPlaylistRepository) Store(playlist domain.Playlist) error {
+	repo.playlists[playlist.ID()] = playlist
+	return nil
+}
+
+func (repo *mockPlaylistRepository) Remove(id domain.PlaylistID) error {
+	delete(repo.playlists, id)
+	return nil
+}
+
+type mockEventDispatcher struct {
+	events []domain.Event
+}
+
+func (dispatcher *mockEventDispatcher) Dispatch(event domain.Event) error {
+	dispatcher.events = append(dispatcher.events, event)
+	return nil
+}
+
+type mockLogger struct{}
+
+func (logger *mockLogger) WithField(string, interface{}) log.Logger {
+	return logger
+}
+
+func (logger *mockLogger) WithFields(log.Fields) log.Logger {
+	return logger
+}
+
+func (logger *mockLogger) Info(...interface{}) {}
+
+func (logger *mockLogger) Error(error, ...interface{}) {}
@@ -24,7 +24,10 @@ type Handler interface {
 type Transport interface {
 	commonamqp.Channel
 	storedevent.Transport
+	// SetHandler sets handler of events delivered to single instance of service
 	SetHandler(handler Handler)
+	// SetInstanceHandler sets handler of events delivered to every instance, e.g. to invalidate local caches
+	SetInstanceHandler(handler Handler)
 }
 
 func NewIntegrationEventTransport() Transport {
@@ -32,15 +35,20 @@ func NewIntegrationEventTransport() Transport {
 }
 
 type transport struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	handler Handler
+	conn            *amqp.Connection
+	channel         *amqp.Channel
+	handler         Handler
+	instanceHandler Handler
 }
 
 func (t *transport) SetHandler(handler Handler) {
 	t.handler = handler
 }
 
+func (t *transport) SetInstanceHandler(handler Handler) {
+	t.instanceHandler = handler
+}
+
 func (t *transport) Name() string {
 	return transportName
 }
@@ -73,16 +81,32 @@ func (t *transport) Connect(conn *amqp.Connection) error {
 		return errors.New("cannot connect to read channel without handler")
 	}
 
-	return t.connectToReadChannel()
-}
-
-func (t *transport) connectToReadChannel() error {
+	// shared durable queue is consumed by competing instances
 	queue, err := t.channel.QueueDeclare(domainEventsQueueName, true, false, false, false, nil)
 	if err != nil {
 		return err
 	}
 
-	err = t.channel.QueueBind(queue.Name, "", domainEventExchangeName, false, nil)
+	err = t.connectToReadChannel(queue, t.handler)
+	if err != nil {
+		return err
+	}
+
+	if t.instanceHandler == nil {
+		return nil
+	}
+
+	// server named exclusive queue exists while instance is connected and receives copy of every event
+	instanceQueue, err := t.channel.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return err
+	}
+
+	return t.connectToReadChannel(instanceQueue, t.instanceHandler)
+}
+
+func (t *transport) connectToReadChannel(queue amqp.Queue, handler Handler) error {
+	err := t.channel.QueueBind(queue.Name, "", domainEventExchangeName, false, nil)
 	if err != nil {
 		return err
 	}
@@ -94,13 +118,13 @@ func (t *transport) connectToReadChannel() error {
 
 	go func() {
 		for delivery := range readChan {
-			err = t.handler.Handle(string(delivery.Body))
-			if err == nil {
-				err = delivery.Ack(false)
+			handleErr := handler.Handle(string(delivery.Body))
+			if handleErr == nil {
+				handleErr = delivery.Ack(false)
 			} else {
-				err = delivery.Nack(false, true)
+				handleErr = delivery.Nack(false, true)
 			}
-			_ = err
+			_ = handleErr
 		}
 	}()
 
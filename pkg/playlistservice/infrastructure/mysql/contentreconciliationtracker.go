@@ -0,0 +1,72 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+const (
+	contentReconciliationLockName   = "content-reconciliation-lock"
+	contentReconciliationCursorName = "content"
+)
+
+var ErrContentReconciliationLockNotAcquired = errors.New("lock for content reconciliation not acquired")
+
+func NewContentReconciliationTracker(client mysql.TransactionalClient) service.ContentReconciliationTracker {
+	return &contentReconciliationTracker{
+		namedLock: newNamedLock(client, contentReconciliationLockName, ErrContentReconciliationLockNotAcquired),
+		client:    client,
+	}
+}
+
+type contentReconciliationTracker struct {
+	*namedLock
+	client mysql.TransactionalClient
+}
+
+func (tracker *contentReconciliationTracker) Cursor() (*uuid.UUID, error) {
+	const selectQuery = `SELECT last_content_id FROM content_reconciliation_cursor WHERE name = ?`
+
+	var id []byte
+	err := tracker.client.Get(&id, selectQuery, contentReconciliationCursorName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	if id == nil {
+		return nil, nil
+	}
+
+	cursor, err := uuid.FromBytes(id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &cursor, nil
+}
+
+func (tracker *contentReconciliationTracker) SaveCursor(cursor *uuid.UUID) error {
+	const insertQuery = `
+		INSERT INTO content_reconciliation_cursor (name, last_content_id, updated_at) VALUES (?, ?, now())
+		ON DUPLICATE KEY UPDATE last_content_id=VALUES(last_content_id), updated_at=VALUES(updated_at)
+	`
+
+	var binaryID []byte
+	if cursor != nil {
+		var err error
+		binaryID, err = cursor.MarshalBinary()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	_, err := tracker.client.Exec(insertQuery, contentReconciliationCursorName, binaryID)
+	return errors.WithStack(err)
+}
@@ -2,7 +2,6 @@ package mysql
 
 import (
 	"database/sql"
-	"sync"
 
 	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/storedevent"
 	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
@@ -10,19 +9,25 @@ import (
 	"github.com/pkg/errors"
 )
 
-const dispatchTrackerLockName = "dispatch-tracker-lock"
+const (
+	dispatchTrackerLockName   = "dispatch-tracker-lock"
+	projectionTrackerLockName = "projection-tracker-lock"
+)
 
 var ErrLockNotAcquired = errors.New("lock for dispatch tracker not acquired")
 
 func NewEventsDispatchTracker(client mysql.TransactionalClient) storedevent.EventsDispatchTracker {
-	return &eventsDispatchTracker{client: client}
+	return &eventsDispatchTracker{namedLock: newNamedLock(client, dispatchTrackerLockName, ErrLockNotAcquired), client: client}
+}
+
+// NewProjectionTracker tracks events of read model projectors under own lock, so projection does not wait for dispatch to broker
+func NewProjectionTracker(client mysql.TransactionalClient) storedevent.EventsDispatchTracker {
+	return &eventsDispatchTracker{namedLock: newNamedLock(client, projectionTrackerLockName, ErrLockNotAcquired), client: client}
 }
 
 type eventsDispatchTracker struct {
-	client      mysql.TransactionalClient
-	mutex       sync.Mutex
-	transaction mysql.Transaction
-	lock        *mysql.Lock
+	*namedLock
+	client mysql.TransactionalClient
 }
 
 func (tracker *eventsDispatchTracker) TrackLastID(transportName string, id storedevent.ID) error {
@@ -55,53 +60,3 @@ func (tracker *eventsDispatchTracker) LastID(transportName string) (*storedevent
 	ID := storedevent.ID(id)
 	return &ID, nil
 }
-
-func (tracker *eventsDispatchTracker) Lock() error {
-	tracker.mutex.Lock()
-
-	if tracker.transaction == nil {
-		var err error
-		tracker.transaction, err = tracker.client.BeginTransaction()
-		if err != nil {
-			return err
-		}
-	}
-
-	l := mysql.NewLock(tracker.transaction, dispatchTrackerLockName)
-	err := l.Lock()
-	if err != nil {
-		return err
-	}
-
-	tracker.lock = &l
-
-	return nil
-}
-
-func (tracker *eventsDispatchTracker) Unlock() (err error) {
-	defer tracker.mutex.Unlock()
-
-	defer func() {
-		if err != nil {
-			transactionErr := tracker.transaction.Rollback()
-			if transactionErr != nil {
-				err = errors.Wrap(err, transactionErr.Error())
-			}
-		} else {
-			err = tracker.transaction.Commit()
-		}
-		tracker.transaction = nil
-	}()
-
-	if tracker.transaction == nil {
-		return ErrLockNotAcquired
-	}
-
-	err = tracker.lock.Unlock()
-	if err != nil {
-		return err
-	}
-	tracker.lock = nil
-
-	return err
-}
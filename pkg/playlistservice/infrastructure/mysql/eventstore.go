@@ -31,7 +31,7 @@ func (store *eventStore) GetAllAfter(id *storedevent.ID) ([]storedevent.StoredEv
 	var args []interface{}
 
 	if id != nil {
-		selectSQL += " WHERE created_at > (SELECT created_at FROM stored_event WHERE stored_event_id = ?)"
+		selectSQL += " WHERE sequence > (SELECT sequence FROM stored_event WHERE stored_event_id = ?)"
 		binaryID, err := uuid.UUID(*id).MarshalBinary()
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -39,7 +39,8 @@ func (store *eventStore) GetAllAfter(id *storedevent.ID) ([]storedevent.StoredEv
 		args = append(args, binaryID)
 	}
 
-	selectSQL += " ORDER BY created_at"
+	// sequence keeps order of events appended within the same second unlike created_at
+	selectSQL += " ORDER BY sequence"
 
 	var storedEvents []sqlxStoredEvent
 
@@ -0,0 +1,74 @@
+package mysql
+
+import (
+	"sync"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+// namedLock holds mysql named lock on connection of own transaction,
+// so lock is released by server when instance dies while holding it
+type namedLock struct {
+	client         mysql.TransactionalClient
+	name           string
+	errNotAcquired error
+	mutex          sync.Mutex
+	transaction    mysql.Transaction
+	lock           *mysql.Lock
+}
+
+func newNamedLock(client mysql.TransactionalClient, name string, errNotAcquired error) *namedLock {
+	return &namedLock{client: client, name: name, errNotAcquired: errNotAcquired}
+}
+
+func (l *namedLock) Lock() error {
+	l.mutex.Lock()
+
+	transaction, err := l.client.BeginTransaction()
+	if err != nil {
+		l.mutex.Unlock()
+		return errors.WithStack(err)
+	}
+
+	lock := mysql.NewLock(transaction, l.name)
+	err = lock.Lock()
+	if err != nil {
+		rollbackErr := transaction.Rollback()
+		l.mutex.Unlock()
+		if rollbackErr != nil {
+			return errors.Wrap(err, rollbackErr.Error())
+		}
+		return err
+	}
+
+	l.transaction = transaction
+	l.lock = &lock
+
+	return nil
+}
+
+func (l *namedLock) Unlock() (err error) {
+	defer l.mutex.Unlock()
+
+	if l.transaction == nil {
+		return l.errNotAcquired
+	}
+
+	defer func() {
+		if err != nil {
+			transactionErr := l.transaction.Rollback()
+			if transactionErr != nil {
+				err = errors.Wrap(err, transactionErr.Error())
+			}
+		} else {
+			err = l.transaction.Commit()
+		}
+		l.transaction = nil
+	}()
+
+	err = l.lock.Unlock()
+	l.lock = nil
+
+	return err
+}
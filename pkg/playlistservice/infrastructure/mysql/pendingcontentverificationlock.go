@@ -0,0 +1,16 @@
+package mysql
+
+import (
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+const pendingContentVerificationLockName = "pending-content-verification-lock"
+
+var ErrPendingContentVerificationLockNotAcquired = errors.New("lock for pending content verification not acquired")
+
+func NewPendingContentVerificationLock(client mysql.TransactionalClient) service.PendingContentVerificationLock {
+	return newNamedLock(client, pendingContentVerificationLockName, ErrPendingContentVerificationLockNotAcquired)
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+func NewAuditLogQueryService(client mysql.Client) query.AuditLogQueryService {
+	return &auditLogQueryService{client: client}
+}
+
+type auditLogQueryService struct {
+	client mysql.Client
+}
+
+func (service *auditLogQueryService) GetAuditLog(spec query.AuditLogSpecification) ([]query.AuditRecordView, error) {
+	selectSQL := `SELECT * FROM audit_record WHERE playlist_id = ?`
+
+	playlistID, err := spec.PlaylistID.MarshalBinary()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	args := []interface{}{playlistID}
+
+	if spec.AfterID != nil {
+		selectSQL += ` AND audit_record_id < ?`
+		args = append(args, *spec.AfterID)
+	}
+
+	selectSQL += ` ORDER BY audit_record_id DESC LIMIT ?`
+	args = append(args, spec.Limit)
+
+	var records []sqlxAuditRecordView
+
+	err = service.client.Select(&records, selectSQL, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]query.AuditRecordView, len(records))
+	for i, record := range records {
+		result[i] = query.AuditRecordView{
+			ID:         record.ID,
+			PlaylistID: record.PlaylistID,
+			OwnerID:    record.OwnerID,
+			ActorID:    record.ActorID,
+			Command:    record.Command,
+			Diff:       record.Diff,
+			RequestID:  record.RequestID,
+			SourceIP:   record.SourceIP,
+			UserAgent:  record.UserAgent,
+			CreatedAt:  record.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
+
+type sqlxAuditRecordView struct {
+	ID         int64     `db:"audit_record_id"`
+	PlaylistID uuid.UUID `db:"playlist_id"`
+	OwnerID    uuid.UUID `db:"owner_id"`
+	ActorID    uuid.UUID `db:"actor_id"`
+	Command    string    `db:"command"`
+	Diff       string    `db:"diff"`
+	RequestID  string    `db:"request_id"`
+	SourceIP   string    `db:"source_ip"`
+	UserAgent  string    `db:"user_agent"`
+	CreatedAt  time.Time `db:"created_at"`
+}
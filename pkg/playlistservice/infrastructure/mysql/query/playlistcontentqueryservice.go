@@ -0,0 +1,102 @@
+package query
+
+import (
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+func NewPlaylistContentQueryService(client mysql.Client) query.PlaylistContentQueryService {
+	return &playlistContentQueryService{client: client}
+}
+
+type playlistContentQueryService struct {
+	client mysql.Client
+}
+
+func (service *playlistContentQueryService) GetOwnerPlaylistsContainingContent(ownerID uuid.UUID, contentIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	const selectSQL = `
+		SELECT DISTINCT pi.content_id, pi.playlist_id FROM playlist_item pi
+		INNER JOIN playlist p ON p.playlist_id = pi.playlist_id
+		WHERE p.owner_id = ? AND pi.content_id IN (?)
+		ORDER BY pi.content_id, pi.playlist_id
+	`
+
+	if len(contentIDs) == 0 {
+		return map[uuid.UUID][]uuid.UUID{}, nil
+	}
+
+	binaryOwnerID, err := ownerID.MarshalBinary()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ids, err := uuidsToBinaryUUIDs(contentIDs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sqlQuery, args, err := sqlx.In(selectSQL, binaryOwnerID, ids)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var rows []struct {
+		ContentID  uuid.UUID `db:"content_id"`
+		PlaylistID uuid.UUID `db:"playlist_id"`
+	}
+
+	err = service.client.Select(&rows, sqlQuery, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make(map[uuid.UUID][]uuid.UUID, len(contentIDs))
+	for _, row := range rows {
+		result[row.ContentID] = append(result[row.ContentID], row.PlaylistID)
+	}
+
+	return result, nil
+}
+
+func (service *playlistContentQueryService) CountPlaylistsContainingContent(contentIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	const selectSQL = `
+		SELECT content_id, COUNT(DISTINCT playlist_id) AS playlist_count FROM playlist_item
+		WHERE content_id IN (?)
+		GROUP BY content_id
+	`
+
+	if len(contentIDs) == 0 {
+		return map[uuid.UUID]int{}, nil
+	}
+
+	ids, err := uuidsToBinaryUUIDs(contentIDs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sqlQuery, args, err := sqlx.In(selectSQL, ids)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var rows []struct {
+		ContentID     uuid.UUID `db:"content_id"`
+		PlaylistCount int       `db:"playlist_count"`
+	}
+
+	err = service.client.Select(&rows, sqlQuery, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make(map[uuid.UUID]int, len(contentIDs))
+	for _, row := range rows {
+		result[row.ContentID] = row.PlaylistCount
+	}
+
+	return result, nil
+}
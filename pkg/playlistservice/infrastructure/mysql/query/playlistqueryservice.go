@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 
 	"playlistservice/pkg/playlistservice/app/query"
+	"playlistservice/pkg/playlistservice/domain"
 )
 
 func NewPlaylistQueryService(client mysql.Client) query.PlaylistQueryService {
@@ -22,15 +23,10 @@ type playlistQueryService struct {
 }
 
 func (service *playlistQueryService) GetPlaylists(spec query.PlaylistSpecification) ([]query.PlaylistView, error) {
-	selectSQL := `SELECT * FROM playlist`
-
-	conditions, args, err := getWhereConditionsBySpec(spec)
+	selectSQL, args, err := selectPlaylistsSQL(`SELECT p.* FROM playlist p`, spec)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	if conditions != "" {
-		selectSQL += fmt.Sprintf(` WHERE %s`, conditions)
-	}
 
 	var playlists []sqlxPlaylistView
 
@@ -48,7 +44,7 @@ func (service *playlistQueryService) GetPlaylists(spec query.PlaylistSpecificati
 		playlistsIDs[i] = playlist.ID
 	}
 
-	playlistsItemsMap, err := service.getPlaylistsItemsMap(playlistsIDs)
+	playlistsItemsMap, err := service.getPlaylistsItemsMap(playlistsIDs, spec.ItemsLimit)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -57,11 +53,16 @@ func (service *playlistQueryService) GetPlaylists(spec query.PlaylistSpecificati
 
 	for i, playlist := range playlists {
 		result[i] = query.PlaylistView{
-			ID:        playlist.ID,
-			Name:      playlist.Name,
-			OwnerID:   playlist.OwnerID,
-			CreatedAt: playlist.CreatedAt,
-			UpdatedAt: playlist.UpdatedAt,
+			ID:                   playlist.ID,
+			Name:                 playlist.Name,
+			OwnerID:              playlist.OwnerID,
+			CreatedAt:            playlist.CreatedAt,
+			UpdatedAt:            playlist.UpdatedAt,
+			Version:              playlist.Version,
+			ItemCount:            playlist.ItemCount,
+			DistinctContentCount: playlist.DistinctContentCount,
+			LastItemAddedAt:      playlist.LastItemAddedAt,
+			Discoverable:         playlist.Discoverable,
 		}
 		items, ok := playlistsItemsMap[playlist.ID]
 		if !ok {
@@ -73,8 +74,59 @@ func (service *playlistQueryService) GetPlaylists(spec query.PlaylistSpecificati
 	return result, nil
 }
 
-func (service *playlistQueryService) getPlaylistsItemsMap(playlistIDs []uuid.UUID) (map[uuid.UUID][]sqlxPlaylistItemView, error) {
-	playlistsItems, err := service.getPlaylistsItems(playlistIDs)
+func (service *playlistQueryService) CountPlaylists(spec query.PlaylistSpecification) (int, error) {
+	return countPlaylists(service.client, "playlist", spec)
+}
+
+func (service *playlistQueryService) GetPlaylistItems(spec query.PlaylistItemSpecification) ([]query.PlaylistItemView, error) {
+	selectSQL := `SELECT * FROM playlist_item WHERE playlist_id = ?`
+
+	playlistID, err := spec.PlaylistID.MarshalBinary()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	args := []interface{}{playlistID}
+
+	sortColumn := playlistItemSortColumn(spec.Sort.Field)
+	comparison, direction := ">", "ASC"
+	if spec.Sort.Descending {
+		comparison, direction = "<", "DESC"
+	}
+
+	if spec.After != nil {
+		cursorID, err2 := spec.After.ID.MarshalBinary()
+		if err2 != nil {
+			return nil, errors.WithStack(err2)
+		}
+		cursorValue := playlistItemCursorValue(spec.Sort.Field, *spec.After)
+
+		selectSQL += fmt.Sprintf(` AND (%[1]s %[2]s ? OR (%[1]s = ? AND playlist_item_id %[2]s ?))`, sortColumn, comparison)
+		args = append(args, cursorValue, cursorValue, cursorID)
+	}
+
+	selectSQL += fmt.Sprintf(` ORDER BY %[1]s %[2]s, playlist_item_id %[2]s`, sortColumn, direction)
+
+	if spec.Limit > 0 {
+		selectSQL += ` LIMIT ?`
+		args = append(args, spec.Limit)
+	}
+
+	var items []sqlxPlaylistItemView
+
+	err = service.client.Select(&items, selectSQL, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return convertToPlaylistItemViews(items), nil
+}
+
+func (service *playlistQueryService) getPlaylistsItemsMap(playlistIDs []uuid.UUID, itemsLimit int) (map[uuid.UUID][]sqlxPlaylistItemView, error) {
+	if itemsLimit == query.NoItems {
+		return nil, nil
+	}
+
+	playlistsItems, err := service.getPlaylistsItems(playlistIDs, itemsLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -93,24 +145,99 @@ func (service *playlistQueryService) getPlaylistsItemsMap(playlistIDs []uuid.UUI
 	return result, nil
 }
 
-func (service *playlistQueryService) getPlaylistsItems(playlistIDs []uuid.UUID) ([]sqlxPlaylistItemView, error) {
+// getPlaylistsItems limits items of each playlist in separate union part since window functions are not available
+func (service *playlistQueryService) getPlaylistsItems(playlistIDs []uuid.UUID, itemsLimit int) ([]sqlxPlaylistItemView, error) {
 	ids, err := uuidsToBinaryUUIDs(playlistIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	sqlQuery, args, err := sqlx.In(`SELECT * FROM playlist_item WHERE playlist_id IN (?)`, ids)
-	if err != nil {
-		return nil, err
+	if itemsLimit <= 0 {
+		sqlQuery, args, err2 := sqlx.In(`SELECT * FROM playlist_item WHERE playlist_id IN (?) ORDER BY playlist_id, position`, ids)
+		if err2 != nil {
+			return nil, err2
+		}
+
+		var playlistItems []sqlxPlaylistItemView
+
+		err = service.client.Select(&playlistItems, sqlQuery, args...)
+
+		return playlistItems, err
+	}
+
+	parts := make([]string, 0, len(ids))
+	args := make([]interface{}, 0, len(ids)*2)
+	for _, id := range ids {
+		parts = append(parts, `(SELECT * FROM playlist_item WHERE playlist_id = ? ORDER BY position LIMIT ?)`)
+		args = append(args, id, itemsLimit)
 	}
 
 	var playlistItems []sqlxPlaylistItemView
 
-	err = service.client.Select(&playlistItems, sqlQuery, args...)
+	err = service.client.Select(&playlistItems, strings.Join(parts, " UNION ALL "), args...)
 
 	return playlistItems, err
 }
 
+// selectPlaylistsSQL completes select from playlists aliased as p with spec conditions, cursor, order and limit
+func selectPlaylistsSQL(selectSQL string, spec query.PlaylistSpecification) (string, []interface{}, error) {
+	conditions, args, err := getWhereConditionsBySpec(spec)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sortExpression := playlistSortExpression(spec.Sort.Field)
+	comparison, direction := ">", "ASC"
+	if spec.Sort.Descending {
+		comparison, direction = "<", "DESC"
+	}
+
+	if spec.After != nil {
+		cursorID, err2 := spec.After.ID.MarshalBinary()
+		if err2 != nil {
+			return "", nil, err2
+		}
+		cursorValue := playlistCursorValue(spec.Sort.Field, *spec.After)
+
+		cursorCondition := fmt.Sprintf(`(%[1]s %[2]s ? OR (%[1]s = ? AND p.playlist_id %[2]s ?))`, sortExpression, comparison)
+		if conditions != "" {
+			conditions += " AND "
+		}
+		conditions += cursorCondition
+		args = append(args, cursorValue, cursorValue, cursorID)
+	}
+
+	if conditions != "" {
+		selectSQL += fmt.Sprintf(` WHERE %s`, conditions)
+	}
+
+	selectSQL += fmt.Sprintf(` ORDER BY %[1]s %[2]s, p.playlist_id %[2]s`, sortExpression, direction)
+
+	if spec.Limit > 0 {
+		selectSQL += ` LIMIT ?`
+		args = append(args, spec.Limit)
+	}
+
+	return selectSQL, args, nil
+}
+
+func countPlaylists(client mysql.Client, table string, spec query.PlaylistSpecification) (int, error) {
+	selectSQL := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, table)
+
+	conditions, args, err := getWhereConditionsBySpec(spec)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if conditions != "" {
+		selectSQL += fmt.Sprintf(` WHERE %s`, conditions)
+	}
+
+	var count int
+
+	err = client.Get(&count, selectSQL, args...)
+	return count, errors.WithStack(err)
+}
+
 //nolint
 func getWhereConditionsBySpec(spec query.PlaylistSpecification) (string, []interface{}, error) {
 	var conditions []string
@@ -125,6 +252,18 @@ func getWhereConditionsBySpec(spec query.PlaylistSpecification) (string, []inter
 		if err != nil {
 			return "", nil, errors.WithStack(err)
 		}
+		if len(spec.SharedPlaylistIDs) != 0 {
+			sharedIDs, err := uuidsToBinaryUUIDs(spec.SharedPlaylistIDs)
+			if err != nil {
+				return "", nil, errors.WithStack(err)
+			}
+			sharedQuery, sharedArgs, err := sqlx.In(`playlist_id IN (?)`, sharedIDs)
+			if err != nil {
+				return "", nil, errors.WithStack(err)
+			}
+			sqlQuery = fmt.Sprintf(`(%s OR %s)`, sqlQuery, sharedQuery)
+			args = append(args, sharedArgs...)
+		}
 		conditions = append(conditions, sqlQuery)
 		for _, arg := range args {
 			params = append(params, arg)
@@ -149,6 +288,46 @@ func getWhereConditionsBySpec(spec query.PlaylistSpecification) (string, []inter
 	return strings.Join(conditions, " AND "), params, nil
 }
 
+func playlistSortExpression(field query.PlaylistSortField) string {
+	switch field {
+	case query.PlaylistSortByName:
+		return "p.name"
+	case query.PlaylistSortByUpdatedAt:
+		return "p.updated_at"
+	case query.PlaylistSortByItemCount:
+		return "p.item_count"
+	default:
+		return "p.created_at"
+	}
+}
+
+func playlistCursorValue(field query.PlaylistSortField, cursor query.PlaylistCursor) interface{} {
+	switch field {
+	case query.PlaylistSortByName:
+		return cursor.Name
+	case query.PlaylistSortByUpdatedAt:
+		return cursor.UpdatedAt
+	case query.PlaylistSortByItemCount:
+		return cursor.ItemCount
+	default:
+		return cursor.CreatedAt
+	}
+}
+
+func playlistItemSortColumn(field query.PlaylistItemSortField) string {
+	if field == query.PlaylistItemSortByCreatedAt {
+		return "created_at"
+	}
+	return "position"
+}
+
+func playlistItemCursorValue(field query.PlaylistItemSortField, cursor query.PlaylistItemCursor) interface{} {
+	if field == query.PlaylistItemSortByCreatedAt {
+		return cursor.CreatedAt
+	}
+	return cursor.Position
+}
+
 func uuidsToBinaryUUIDs(uuids []uuid.UUID) ([][]byte, error) {
 	res := make([][]byte, len(uuids))
 	for i, id := range uuids {
@@ -171,23 +350,33 @@ func convertToPlaylistItemViews(views []sqlxPlaylistItemView) []query.PlaylistIt
 
 func convertToPlaylistItemView(view sqlxPlaylistItemView) query.PlaylistItemView {
 	return query.PlaylistItemView{
-		ID:        view.ID,
-		ContentID: view.ContentID,
-		CreatedAt: view.CreatedAt,
+		ID:                  view.ID,
+		ContentID:           view.ContentID,
+		PendingVerification: view.Availability == int(domain.PlaylistItemPendingVerification),
+		Unavailable:         view.Availability == int(domain.PlaylistItemUnavailable),
+		CreatedAt:           view.CreatedAt,
+		Position:            view.Position,
 	}
 }
 
 type sqlxPlaylistView struct {
-	ID        uuid.UUID `db:"playlist_id"`
-	Name      string    `db:"name"`
-	OwnerID   uuid.UUID `db:"owner_id"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID                   uuid.UUID  `db:"playlist_id"`
+	Name                 string     `db:"name"`
+	OwnerID              uuid.UUID  `db:"owner_id"`
+	CreatedAt            time.Time  `db:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at"`
+	Version              int        `db:"version"`
+	ItemCount            int        `db:"item_count"`
+	DistinctContentCount int        `db:"distinct_content_count"`
+	LastItemAddedAt      *time.Time `db:"last_item_added_at"`
+	Discoverable         bool       `db:"discoverable"`
 }
 
 type sqlxPlaylistItemView struct {
-	ID         uuid.UUID  `db:"playlist_item_id"`
-	PlaylistID uuid.UUID  `db:"playlist_id"`
-	ContentID  uuid.UUID  `db:"content_id"`
-	CreatedAt  *time.Time `db:"created_at"`
+	ID           uuid.UUID  `db:"playlist_item_id"`
+	PlaylistID   uuid.UUID  `db:"playlist_id"`
+	ContentID    uuid.UUID  `db:"content_id"`
+	Availability int        `db:"availability"`
+	CreatedAt    *time.Time `db:"created_at"`
+	Position     int64      `db:"position"`
 }
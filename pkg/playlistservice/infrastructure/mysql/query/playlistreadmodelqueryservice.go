@@ -0,0 +1,199 @@
+package query
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+// ReadModelPlaylistItem is element of items serialized to playlist read model ordered by position
+type ReadModelPlaylistItem struct {
+	ID           uuid.UUID  `json:"id"`
+	ContentID    uuid.UUID  `json:"content_id"`
+	Availability int        `json:"availability"`
+	CreatedAt    *time.Time `json:"created_at"`
+	Position     int64      `json:"position"`
+}
+
+// NewPlaylistReadModelQueryService serves playlists from read model kept by projector, so it lags behind writes.
+// Items pages are cut from the same read model row, so they agree with item count of playlist
+func NewPlaylistReadModelQueryService(client mysql.Client) query.PlaylistQueryService {
+	return &playlistReadModelQueryService{client: client}
+}
+
+type playlistReadModelQueryService struct {
+	client mysql.Client
+}
+
+func (service *playlistReadModelQueryService) GetPlaylists(spec query.PlaylistSpecification) ([]query.PlaylistView, error) {
+	const playlistColumns = `p.playlist_id, p.name, p.owner_id, p.created_at, p.updated_at, p.version, p.item_count, p.distinct_content_count, p.last_item_added_at, p.discoverable`
+
+	// serialized items are the largest part of row, so they are not read unless requested
+	columns := playlistColumns
+	if spec.ItemsLimit != query.NoItems {
+		columns += `, p.items`
+	}
+
+	selectSQL, args, err := selectPlaylistsSQL(fmt.Sprintf(`SELECT %s FROM playlist_read_model p`, columns), spec)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var playlists []sqlxPlaylistReadModel
+
+	err = service.client.Select(&playlists, selectSQL, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(playlists) == 0 {
+		return nil, nil
+	}
+
+	result := make([]query.PlaylistView, len(playlists))
+
+	for i, playlist := range playlists {
+		result[i] = query.PlaylistView{
+			ID:                   playlist.ID,
+			Name:                 playlist.Name,
+			OwnerID:              playlist.OwnerID,
+			CreatedAt:            playlist.CreatedAt,
+			UpdatedAt:            playlist.UpdatedAt,
+			Version:              playlist.Version,
+			ItemCount:            playlist.ItemCount,
+			DistinctContentCount: playlist.DistinctContentCount,
+			LastItemAddedAt:      playlist.LastItemAddedAt,
+			Discoverable:         playlist.Discoverable,
+		}
+
+		if spec.ItemsLimit == query.NoItems {
+			continue
+		}
+
+		items, err2 := decodeReadModelItems(playlist.Items, spec.ItemsLimit)
+		if err2 != nil {
+			return nil, errors.WithStack(err2)
+		}
+		if len(items) != 0 {
+			result[i].PlaylistItems = items
+		}
+	}
+
+	return result, nil
+}
+
+func (service *playlistReadModelQueryService) CountPlaylists(spec query.PlaylistSpecification) (int, error) {
+	return countPlaylists(service.client, "playlist_read_model", spec)
+}
+
+func (service *playlistReadModelQueryService) GetPlaylistItems(spec query.PlaylistItemSpecification) ([]query.PlaylistItemView, error) {
+	const selectSQL = `SELECT items FROM playlist_read_model WHERE playlist_id = ?`
+
+	playlistID, err := spec.PlaylistID.MarshalBinary()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var data string
+	err = service.client.Get(&data, selectSQL, playlistID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	items, err := decodeReadModelItems(data, 0)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return pagePlaylistItems(items, spec), nil
+}
+
+// pagePlaylistItems orders items like playlist_item table query does and returns page after cursor
+func pagePlaylistItems(items []query.PlaylistItemView, spec query.PlaylistItemSpecification) []query.PlaylistItemView {
+	compare := func(a, b query.PlaylistItemCursor) int {
+		var result int
+		if spec.Sort.Field == query.PlaylistItemSortByCreatedAt {
+			result = compareInt64(a.CreatedAt.Unix(), b.CreatedAt.Unix())
+		} else {
+			result = compareInt64(a.Position, b.Position)
+		}
+		if result == 0 {
+			result = bytes.Compare(a.ID[:], b.ID[:])
+		}
+		if spec.Sort.Descending {
+			return -result
+		}
+		return result
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return compare(query.NewPlaylistItemCursor(items[i]), query.NewPlaylistItemCursor(items[j])) < 0
+	})
+
+	start := 0
+	if spec.After != nil {
+		start = sort.Search(len(items), func(i int) bool {
+			return compare(query.NewPlaylistItemCursor(items[i]), *spec.After) > 0
+		})
+	}
+
+	items = items[start:]
+	if spec.Limit > 0 && len(items) > spec.Limit {
+		items = items[:spec.Limit]
+	}
+	return items
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func decodeReadModelItems(data string, itemsLimit int) ([]query.PlaylistItemView, error) {
+	var items []ReadModelPlaylistItem
+	err := json.Unmarshal([]byte(data), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	if itemsLimit > 0 && len(items) > itemsLimit {
+		items = items[:itemsLimit]
+	}
+
+	result := make([]query.PlaylistItemView, len(items))
+	for i, item := range items {
+		result[i] = query.PlaylistItemView{
+			ID:                  item.ID,
+			ContentID:           item.ContentID,
+			PendingVerification: item.Availability == int(domain.PlaylistItemPendingVerification),
+			Unavailable:         item.Availability == int(domain.PlaylistItemUnavailable),
+			CreatedAt:           item.CreatedAt,
+			Position:            item.Position,
+		}
+	}
+	return result, nil
+}
+
+type sqlxPlaylistReadModel struct {
+	sqlxPlaylistView
+	Items string `db:"items"`
+}
@@ -0,0 +1,83 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+func TestPagePlaylistItems(t *testing.T) {
+	createdAt := time.Unix(1000, 0)
+	later := createdAt.Add(time.Minute)
+
+	// first and third items share creation time, so creation time order falls back to id
+	first := query.PlaylistItemView{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Position: 3, CreatedAt: &createdAt}
+	second := query.PlaylistItemView{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Position: 1, CreatedAt: &later}
+	third := query.PlaylistItemView{ID: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Position: 2, CreatedAt: &createdAt}
+
+	cursor := func(view query.PlaylistItemView) *query.PlaylistItemCursor {
+		c := query.NewPlaylistItemCursor(view)
+		return &c
+	}
+
+	testCases := []struct {
+		name     string
+		spec     query.PlaylistItemSpecification
+		expected []query.PlaylistItemView
+	}{
+		{
+			name:     "by position",
+			spec:     query.PlaylistItemSpecification{},
+			expected: []query.PlaylistItemView{second, third, first},
+		},
+		{
+			name:     "by position descending",
+			spec:     query.PlaylistItemSpecification{Sort: query.PlaylistItemSort{Descending: true}},
+			expected: []query.PlaylistItemView{first, third, second},
+		},
+		{
+			name:     "by creation time",
+			spec:     query.PlaylistItemSpecification{Sort: query.PlaylistItemSort{Field: query.PlaylistItemSortByCreatedAt}},
+			expected: []query.PlaylistItemView{first, third, second},
+		},
+		{
+			name:     "limit",
+			spec:     query.PlaylistItemSpecification{Limit: 2},
+			expected: []query.PlaylistItemView{second, third},
+		},
+		{
+			name:     "after cursor",
+			spec:     query.PlaylistItemSpecification{After: cursor(second), Limit: 1},
+			expected: []query.PlaylistItemView{third},
+		},
+		{
+			name: "after cursor by creation time",
+			spec: query.PlaylistItemSpecification{
+				Sort:  query.PlaylistItemSort{Field: query.PlaylistItemSortByCreatedAt},
+				After: cursor(first),
+			},
+			expected: []query.PlaylistItemView{third, second},
+		},
+		{
+			name: "after cursor of removed item",
+			spec: query.PlaylistItemSpecification{
+				After: &query.PlaylistItemCursor{ID: uuid.MustParse("ffffffff-0000-0000-0000-000000000000"), Position: 2},
+			},
+			expected: []query.PlaylistItemView{first},
+		},
+		{
+			name:     "after last item",
+			spec:     query.PlaylistItemSpecification{After: cursor(first)},
+			expected: []query.PlaylistItemView{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		items := []query.PlaylistItemView{first, second, third}
+		assert.Equal(t, testCase.expected, pagePlaylistItems(items, testCase.spec), testCase.name)
+	}
+}
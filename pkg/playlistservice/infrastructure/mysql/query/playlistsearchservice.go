@@ -0,0 +1,109 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+// NewPlaylistSearchService searches by FULLTEXT index on playlist name,
+// words shorter than innodb_ft_min_token_size and stopwords are not indexed so they match nothing
+func NewPlaylistSearchService(client mysql.Client) query.PlaylistSearchService {
+	return &playlistSearchService{client: client}
+}
+
+type playlistSearchService struct {
+	client mysql.Client
+}
+
+func (service *playlistSearchService) SearchPlaylists(spec query.PlaylistSearchSpecification) ([]query.PlaylistSearchResult, error) {
+	searchQuery := booleanModeSearchQuery(spec.Text)
+	if searchQuery == "" {
+		return nil, nil
+	}
+
+	const matchSQL = `MATCH(p.name) AGAINST(? IN BOOLEAN MODE)`
+
+	selectSQL := fmt.Sprintf(`SELECT p.*, %s AS relevance FROM playlist p`, matchSQL)
+	conditions := []string{matchSQL}
+	args := []interface{}{searchQuery, searchQuery}
+
+	if len(spec.OwnerIDs) != 0 {
+		ownerConditions, ownerArgs, err := getWhereConditionsBySpec(query.PlaylistSpecification{OwnerIDs: spec.OwnerIDs})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		conditions = append(conditions, ownerConditions)
+		args = append(args, ownerArgs...)
+	}
+
+	if spec.CreatedAfter != nil {
+		conditions = append(conditions, `p.created_at >= ?`)
+		args = append(args, *spec.CreatedAfter)
+	}
+	if spec.CreatedBefore != nil {
+		conditions = append(conditions, `p.created_at < ?`)
+		args = append(args, *spec.CreatedBefore)
+	}
+	if spec.UpdatedAfter != nil {
+		conditions = append(conditions, `p.updated_at >= ?`)
+		args = append(args, *spec.UpdatedAfter)
+	}
+	if spec.UpdatedBefore != nil {
+		conditions = append(conditions, `p.updated_at < ?`)
+		args = append(args, *spec.UpdatedBefore)
+	}
+
+	selectSQL += fmt.Sprintf(` WHERE %s ORDER BY relevance DESC, p.playlist_id LIMIT ? OFFSET ?`, strings.Join(conditions, " AND "))
+	args = append(args, spec.Limit, spec.Offset)
+
+	var playlists []sqlxPlaylistSearchResult
+
+	err := service.client.Select(&playlists, selectSQL, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]query.PlaylistSearchResult, len(playlists))
+	for i, playlist := range playlists {
+		result[i] = query.PlaylistSearchResult{
+			Playlist: query.PlaylistView{
+				ID:                   playlist.ID,
+				Name:                 playlist.Name,
+				OwnerID:              playlist.OwnerID,
+				CreatedAt:            playlist.CreatedAt,
+				UpdatedAt:            playlist.UpdatedAt,
+				Version:              playlist.Version,
+				ItemCount:            playlist.ItemCount,
+				DistinctContentCount: playlist.DistinctContentCount,
+				LastItemAddedAt:      playlist.LastItemAddedAt,
+			},
+			Relevance: playlist.Relevance,
+		}
+	}
+
+	return result, nil
+}
+
+// booleanModeSearchQuery requires every word of text as prefix, boolean mode operators are dropped from text
+func booleanModeSearchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = "+" + word + "*"
+	}
+	return strings.Join(terms, " ")
+}
+
+type sqlxPlaylistSearchResult struct {
+	sqlxPlaylistView
+	Relevance float64 `db:"relevance"`
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+func NewStoredEventQueryService(client mysql.Client) query.StoredEventQueryService {
+	return &storedEventQueryService{client: client}
+}
+
+type storedEventQueryService struct {
+	client mysql.Client
+}
+
+// GetUserEvents finds playlists by owner in their created events, events about items carry only playlist id.
+// Events are scanned without index, export runs rarely and in background
+func (service *storedEventQueryService) GetUserEvents(userID uuid.UUID) ([]query.StoredEventView, error) {
+	const selectSQL = `
+		SELECT stored_event_id, type, body, created_at FROM stored_event
+		WHERE JSON_UNQUOTE(JSON_EXTRACT(body, '$.Payload.user_id')) = ?
+		   OR JSON_UNQUOTE(JSON_EXTRACT(body, '$.Payload.playlist_id')) IN (
+			SELECT JSON_UNQUOTE(JSON_EXTRACT(body, '$.Payload.playlist_id')) FROM stored_event
+			WHERE JSON_UNQUOTE(JSON_EXTRACT(body, '$.Payload.owner_id')) = ?
+		)
+		ORDER BY created_at
+	`
+
+	var events []sqlxStoredEventView
+
+	err := service.client.Select(&events, selectSQL, userID.String(), userID.String())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]query.StoredEventView, len(events))
+	for i, event := range events {
+		result[i] = query.StoredEventView{
+			ID:        event.ID,
+			Type:      event.Type,
+			Body:      event.Body,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
+
+type sqlxStoredEventView struct {
+	ID        uuid.UUID `db:"stored_event_id"`
+	Type      string    `db:"type"`
+	Body      string    `db:"body"`
+	CreatedAt time.Time `db:"created_at"`
+}
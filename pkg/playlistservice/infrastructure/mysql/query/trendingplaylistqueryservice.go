@@ -0,0 +1,92 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+// NewTrendingPlaylistQueryService serves ranking refreshed by trending ranker with playlists from read model
+func NewTrendingPlaylistQueryService(client mysql.Client) query.TrendingPlaylistQueryService {
+	return &trendingPlaylistQueryService{client: client}
+}
+
+type trendingPlaylistQueryService struct {
+	client mysql.Client
+}
+
+func (service *trendingPlaylistQueryService) GetTrendingPlaylists(spec query.TrendingPlaylistSpecification) ([]query.TrendingPlaylistView, error) {
+	const playlistColumns = `t.position, t.score, p.playlist_id, p.name, p.owner_id, p.created_at, p.updated_at, p.version, p.item_count, p.distinct_content_count, p.last_item_added_at, p.discoverable`
+
+	columns := playlistColumns
+	if spec.ItemsLimit != query.NoItems {
+		columns += `, p.items`
+	}
+
+	// discoverability is checked on playlist table, so opting out hides playlist before next ranking refresh
+	selectSQL := fmt.Sprintf(`
+		SELECT %s FROM trending_playlist t
+		INNER JOIN playlist_read_model p ON p.playlist_id = t.playlist_id
+		INNER JOIN playlist w ON w.playlist_id = t.playlist_id AND w.discoverable = 1`, columns)
+	conditions := []string{`t.position > ?`}
+	args := []interface{}{spec.AfterPosition}
+
+	if spec.ContentType != nil {
+		conditions = append(conditions, `t.content_types & ? <> 0`)
+		args = append(args, 1<<uint(*spec.ContentType))
+	}
+
+	selectSQL += fmt.Sprintf(` WHERE %s ORDER BY t.position LIMIT ?`, strings.Join(conditions, " AND "))
+	args = append(args, spec.Limit)
+
+	var playlists []sqlxTrendingPlaylist
+
+	err := service.client.Select(&playlists, selectSQL, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]query.TrendingPlaylistView, len(playlists))
+	for i, playlist := range playlists {
+		result[i] = query.TrendingPlaylistView{
+			Playlist: query.PlaylistView{
+				ID:                   playlist.ID,
+				Name:                 playlist.Name,
+				OwnerID:              playlist.OwnerID,
+				CreatedAt:            playlist.CreatedAt,
+				UpdatedAt:            playlist.UpdatedAt,
+				Version:              playlist.Version,
+				ItemCount:            playlist.ItemCount,
+				DistinctContentCount: playlist.DistinctContentCount,
+				LastItemAddedAt:      playlist.LastItemAddedAt,
+				Discoverable:         playlist.Discoverable,
+			},
+			Position: playlist.Position,
+			Score:    playlist.Score,
+		}
+
+		if spec.ItemsLimit == query.NoItems {
+			continue
+		}
+
+		items, err2 := decodeReadModelItems(playlist.Items, spec.ItemsLimit)
+		if err2 != nil {
+			return nil, errors.WithStack(err2)
+		}
+		if len(items) != 0 {
+			result[i].Playlist.PlaylistItems = items
+		}
+	}
+
+	return result, nil
+}
+
+type sqlxTrendingPlaylist struct {
+	sqlxPlaylistReadModel
+	Position int     `db:"position"`
+	Score    float64 `db:"score"`
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+func NewAuditRecordRepository(client mysql.Client) service.AuditRecordRepository {
+	return &auditRecordRepository{
+		client: client,
+	}
+}
+
+type auditRecordRepository struct {
+	client mysql.Client
+}
+
+func (repo *auditRecordRepository) Store(record service.AuditRecord) error {
+	const insertSQL = `
+		INSERT INTO audit_record (playlist_id, owner_id, actor_id, command, diff, request_id, source_ip, user_agent, created_at) 
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	playlistID, err := record.PlaylistID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	ownerID, err := record.OwnerID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	actorID, err := record.ActorID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = repo.client.Exec(
+		insertSQL,
+		playlistID,
+		ownerID,
+		actorID,
+		record.Command,
+		record.Diff,
+		record.RequestID,
+		record.SourceIP,
+		record.UserAgent,
+		record.CreatedAt,
+	)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+func NewDataExportRepository(client mysql.Client) service.DataExportRepository {
+	return &dataExportRepository{
+		client: client,
+	}
+}
+
+type dataExportRepository struct {
+	client mysql.Client
+}
+
+const selectDataExportSQL = `SELECT data_export_id, user_id, include_csv, status, created_at, completed_at FROM data_export`
+
+func (repo *dataExportRepository) Store(export service.DataExport) error {
+	const insertSQL = `
+		INSERT INTO data_export (data_export_id, user_id, include_csv, status, created_at, completed_at) VALUES(?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY
+		UPDATE status=VALUES(status), completed_at=VALUES(completed_at)
+	`
+
+	binaryID, err := export.ID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	binaryUserID, err := export.UserID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = repo.client.Exec(insertSQL, binaryID, binaryUserID, export.IncludeCSV, int(export.Status), export.CreatedAt, export.CompletedAt)
+	return errors.WithStack(err)
+}
+
+func (repo *dataExportRepository) Find(id uuid.UUID) (service.DataExport, error) {
+	const selectSQL = selectDataExportSQL + ` WHERE data_export_id = ?`
+
+	binaryID, err := id.MarshalBinary()
+	if err != nil {
+		return service.DataExport{}, errors.WithStack(err)
+	}
+
+	var export sqlxDataExport
+
+	err = repo.client.Get(&export, selectSQL, binaryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return service.DataExport{}, service.ErrDataExportNotFound
+		}
+		return service.DataExport{}, errors.WithStack(err)
+	}
+
+	return convertDataExport(export), nil
+}
+
+func (repo *dataExportRepository) FindPending(limit int) ([]service.DataExport, error) {
+	const selectSQL = selectDataExportSQL + ` WHERE status = ? ORDER BY created_at LIMIT ?`
+
+	var exports []sqlxDataExport
+
+	err := repo.client.Select(&exports, selectSQL, int(service.DataExportPending), limit)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]service.DataExport, len(exports))
+	for i, export := range exports {
+		result[i] = convertDataExport(export)
+	}
+
+	return result, nil
+}
+
+func (repo *dataExportRepository) StoreArchive(id uuid.UUID, archive []byte) error {
+	const updateSQL = `UPDATE data_export SET archive = ? WHERE data_export_id = ?`
+
+	binaryID, err := id.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = repo.client.Exec(updateSQL, archive, binaryID)
+	return errors.WithStack(err)
+}
+
+func (repo *dataExportRepository) FindArchive(id uuid.UUID) ([]byte, error) {
+	const selectSQL = `SELECT archive FROM data_export WHERE data_export_id = ? AND archive IS NOT NULL`
+
+	binaryID, err := id.MarshalBinary()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var archive []byte
+
+	err = repo.client.Get(&archive, selectSQL, binaryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, service.ErrDataExportNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	return archive, nil
+}
+
+func convertDataExport(export sqlxDataExport) service.DataExport {
+	return service.DataExport{
+		ID:          export.ID,
+		UserID:      export.UserID,
+		IncludeCSV:  export.IncludeCSV,
+		Status:      service.DataExportStatus(export.Status),
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+	}
+}
+
+type sqlxDataExport struct {
+	ID          uuid.UUID  `db:"data_export_id"`
+	UserID      uuid.UUID  `db:"user_id"`
+	IncludeCSV  bool       `db:"include_csv"`
+	Status      int        `db:"status"`
+	CreatedAt   time.Time  `db:"created_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+func NewIdempotencyRecordRepository(client mysql.Client) service.IdempotencyRecordRepository {
+	return &idempotencyRecordRepository{
+		client: client,
+	}
+}
+
+type idempotencyRecordRepository struct {
+	client mysql.Client
+}
+
+func (repo *idempotencyRecordRepository) Find(key string, userID uuid.UUID) (service.IdempotencyRecord, error) {
+	const selectSQL = `SELECT * FROM idempotency_record WHERE idempotency_key = ? AND user_id = ?`
+
+	binaryUserID, err := userID.MarshalBinary()
+	if err != nil {
+		return service.IdempotencyRecord{}, errors.WithStack(err)
+	}
+
+	var record sqlxIdempotencyRecord
+
+	err = repo.client.Get(&record, selectSQL, key, binaryUserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return service.IdempotencyRecord{}, service.ErrIdempotencyRecordNotFound
+		}
+		return service.IdempotencyRecord{}, errors.WithStack(err)
+	}
+
+	return service.IdempotencyRecord{
+		Key:         record.Key,
+		UserID:      record.UserID,
+		RequestHash: record.RequestHash,
+		Response:    record.Response,
+		CreatedAt:   record.CreatedAt,
+	}, nil
+}
+
+func (repo *idempotencyRecordRepository) Store(record service.IdempotencyRecord) error {
+	const insertSQL = `
+		INSERT INTO idempotency_record (idempotency_key, user_id, request_hash, response, created_at) VALUES(?, ?, ?, ?, ?)
+		ON DUPLICATE KEY 
+		UPDATE request_hash=VALUES(request_hash), response=VALUES(response), created_at=VALUES(created_at)
+	`
+
+	binaryUserID, err := record.UserID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = repo.client.Exec(insertSQL, record.Key, binaryUserID, record.RequestHash, record.Response, record.CreatedAt)
+	return errors.WithStack(err)
+}
+
+func (repo *idempotencyRecordRepository) RemoveCreatedBefore(before time.Time, limit int) (int, error) {
+	const deleteSQL = `DELETE FROM idempotency_record WHERE created_at < ? LIMIT ?`
+
+	result, err := repo.client.Exec(deleteSQL, before, limit)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	removed, err := result.RowsAffected()
+	return int(removed), errors.WithStack(err)
+}
+
+type sqlxIdempotencyRecord struct {
+	Key         string    `db:"idempotency_key"`
+	UserID      uuid.UUID `db:"user_id"`
+	RequestHash string    `db:"request_hash"`
+	Response    string    `db:"response"`
+	CreatedAt   time.Time `db:"created_at"`
+}
@@ -32,8 +32,10 @@ func (repo *playlistRepository) NewPlaylistItemID() domain.PlaylistItemID {
 	return domain.PlaylistItemID(uuid.New())
 }
 
+// Find locks playlist row and its items until transaction ends, so commands changing same playlist are serialized
+// even when they hold different named locks, and stored items never miss items added concurrently
 func (repo *playlistRepository) Find(id domain.PlaylistID) (domain.Playlist, error) {
-	const selectSQL = `SELECT * from playlist WHERE playlist_id = ?`
+	const selectSQL = `SELECT playlist_id, name, owner_id, created_at, updated_at, version, discoverable from playlist WHERE playlist_id = ? FOR UPDATE`
 
 	binaryUUID, err := uuid.UUID(id).MarshalBinary()
 	if err != nil {
@@ -56,12 +58,14 @@ func (repo *playlistRepository) Find(id domain.PlaylistID) (domain.Playlist, err
 	}
 
 	return domain.LoadPlaylist(&playlistData{
-		id:        playlist.ID,
-		name:      playlist.Name,
-		ownerID:   playlist.OwnerID,
-		items:     convertPlaylistItems(playlistItems),
-		createdAt: playlist.CreatedAt,
-		updatedAt: playlist.UpdatedAt,
+		id:           playlist.ID,
+		name:         playlist.Name,
+		ownerID:      playlist.OwnerID,
+		items:        convertPlaylistItems(playlistItems),
+		createdAt:    playlist.CreatedAt,
+		updatedAt:    playlist.UpdatedAt,
+		version:      playlist.Version,
+		discoverable: playlist.Discoverable,
 	}), nil
 }
 
@@ -72,11 +76,14 @@ func (repo *playlistRepository) FindByItemID(playlistItemID domain.PlaylistItemI
 			p.name AS name, 
 			p.owner_id AS owner_id, 
 			p.created_at AS created_at, 
-			p.updated_at AS updated_at
+			p.updated_at AS updated_at,
+			p.version AS version,
+			p.discoverable AS discoverable
 		FROM 
 			playlist p 
 		LEFT JOIN playlist_item pi on p.playlist_id = pi.playlist_id 
 		WHERE pi.playlist_item_id = ?
+		FOR UPDATE
 	`
 
 	binaryUUID, err := uuid.UUID(playlistItemID).MarshalBinary()
@@ -100,20 +107,94 @@ func (repo *playlistRepository) FindByItemID(playlistItemID domain.PlaylistItemI
 	}
 
 	return domain.LoadPlaylist(&playlistData{
-		id:        playlist.ID,
-		name:      playlist.Name,
-		ownerID:   playlist.OwnerID,
-		items:     convertPlaylistItems(playlistItems),
-		createdAt: playlist.CreatedAt,
-		updatedAt: playlist.UpdatedAt,
+		id:           playlist.ID,
+		name:         playlist.Name,
+		ownerID:      playlist.OwnerID,
+		items:        convertPlaylistItems(playlistItems),
+		createdAt:    playlist.CreatedAt,
+		updatedAt:    playlist.UpdatedAt,
+		version:      playlist.Version,
+		discoverable: playlist.Discoverable,
 	}), nil
 }
 
+func (repo *playlistRepository) FindAll(spec domain.PlaylistSpecification) ([]domain.Playlist, error) {
+	if len(spec.OwnerIDs) == 0 && len(spec.ContentIDs) == 0 {
+		return nil, nil
+	}
+
+	selectSQL := `SELECT DISTINCT p.playlist_id FROM playlist p`
+	var conditions []string
+	var args []interface{}
+
+	if len(spec.OwnerIDs) != 0 {
+		ownerIDs := make([]uuid.UUID, 0, len(spec.OwnerIDs))
+		for _, ownerID := range spec.OwnerIDs {
+			ownerIDs = append(ownerIDs, uuid.UUID(ownerID))
+		}
+		binaryUUIDs, err := uuidsToBinaryUUIDs(ownerIDs)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, `p.owner_id IN (?)`)
+		args = append(args, binaryUUIDs)
+	}
+
+	if len(spec.ContentIDs) != 0 {
+		contentIDs := make([]uuid.UUID, 0, len(spec.ContentIDs))
+		for _, contentID := range spec.ContentIDs {
+			contentIDs = append(contentIDs, uuid.UUID(contentID))
+		}
+		binaryUUIDs, err := uuidsToBinaryUUIDs(contentIDs)
+		if err != nil {
+			return nil, err
+		}
+		selectSQL += ` INNER JOIN playlist_item pi ON p.playlist_id = pi.playlist_id`
+		conditions = append(conditions, `pi.content_id IN (?)`)
+		args = append(args, binaryUUIDs)
+
+		if spec.ExceptAvailability != nil {
+			conditions = append(conditions, `pi.availability <> ?`)
+			args = append(args, *spec.ExceptAvailability)
+		}
+	}
+
+	selectSQL += ` WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY p.playlist_id`
+	if spec.Limit > 0 {
+		selectSQL += ` LIMIT ?`
+		args = append(args, spec.Limit)
+	}
+
+	query, params, err := sqlx.In(selectSQL, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var playlistIDs []uuid.UUID
+	err = repo.client.Select(&playlistIDs, query, params...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]domain.Playlist, 0, len(playlistIDs))
+	for _, playlistID := range playlistIDs {
+		playlist, err := repo.Find(domain.PlaylistID(playlistID))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, playlist)
+	}
+
+	return result, nil
+}
+
 func (repo *playlistRepository) Store(playlist domain.Playlist) error {
 	const insertSQL = `
-		INSERT INTO playlist (playlist_id, name, owner_id, created_at, updated_at) VALUES(?, ?, ?, ?, ?)
+		INSERT INTO playlist (playlist_id, name, owner_id, created_at, updated_at, version, discoverable, item_count, distinct_content_count, last_item_added_at) 
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY 
-		UPDATE playlist_id=VALUES(playlist_id), name=VALUES(name), owner_id=VALUES(owner_id), created_at=VALUES(created_at), updated_at=VALUES(updated_at)
+		UPDATE playlist_id=VALUES(playlist_id), name=VALUES(name), owner_id=VALUES(owner_id), created_at=VALUES(created_at), updated_at=VALUES(updated_at),
+			version=VALUES(version), discoverable=VALUES(discoverable), item_count=VALUES(item_count), distinct_content_count=VALUES(distinct_content_count), last_item_added_at=VALUES(last_item_added_at)
 	`
 
 	binaryUUID, err := uuid.UUID(playlist.ID()).MarshalBinary()
@@ -126,7 +207,21 @@ func (repo *playlistRepository) Store(playlist domain.Playlist) error {
 		return errors.WithStack(err)
 	}
 
-	_, err = repo.client.Exec(insertSQL, binaryUUID, playlist.Name(), ownerID, playlist.CreatedAt(), playlist.UpdatedAt())
+	stats := calculatePlaylistStats(playlist.Items())
+
+	_, err = repo.client.Exec(
+		insertSQL,
+		binaryUUID,
+		playlist.Name(),
+		ownerID,
+		playlist.CreatedAt(),
+		playlist.UpdatedAt(),
+		playlist.Version(),
+		playlist.Discoverable(),
+		stats.itemCount,
+		stats.distinctContentCount,
+		stats.lastItemAddedAt,
+	)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -166,7 +261,8 @@ func (repo *playlistRepository) Remove(id domain.PlaylistID) error {
 }
 
 func (repo *playlistRepository) fetchPlaylistItems(id uuid.UUID) ([]sqlxPlaylistItem, error) {
-	const selectSQL = `SELECT playlist_item_id, content_id, created_at from playlist_item WHERE playlist_id = ?`
+	// locking read sees items committed after transaction snapshot was taken
+	const selectSQL = `SELECT playlist_item_id, content_id, availability, created_at from playlist_item WHERE playlist_id = ? FOR UPDATE`
 
 	binaryUUID, err := id.MarshalBinary()
 	if err != nil {
@@ -190,9 +286,9 @@ func (repo *playlistRepository) storePlaylistItems(playlistID domain.PlaylistID,
 	}
 
 	const insertSQL = `
-		INSERT INTO playlist_item (playlist_item_id, playlist_id, content_id, created_at) VALUES %s
+		INSERT INTO playlist_item (playlist_item_id, playlist_id, content_id, availability, created_at) VALUES %s
 		ON DUPLICATE KEY 
-		UPDATE playlist_item_id=VALUES(playlist_item_id), playlist_id=VALUES(playlist_id), content_id=VALUES(content_id), created_at=VALUES(created_at)
+		UPDATE playlist_item_id=VALUES(playlist_item_id), playlist_id=VALUES(playlist_id), content_id=VALUES(content_id), availability=VALUES(availability), created_at=VALUES(created_at)
 	`
 
 	values := make([]string, 0, len(items))
@@ -217,9 +313,11 @@ func (repo *playlistRepository) storePlaylistItems(playlistID domain.PlaylistID,
 		}
 		args = append(args, contentID)
 
+		args = append(args, item.Availability())
+
 		args = append(args, item.CreatedAt())
 
-		values = append(values, "(?, ?, ?, ?)")
+		values = append(values, "(?, ?, ?, ?, ?)")
 	}
 
 	_, err := repo.client.Exec(fmt.Sprintf(insertSQL, strings.Join(values, ", ")), args...)
@@ -271,39 +369,69 @@ func (repo playlistRepository) removePlaylistItems(playlistID domain.PlaylistID)
 	return err
 }
 
+// playlistStats are stored with playlist to not scan items on reads, aggregate holds all items so stats are exact
+type playlistStats struct {
+	itemCount            int
+	distinctContentCount int
+	lastItemAddedAt      *time.Time
+}
+
+func calculatePlaylistStats(items map[domain.PlaylistItemID]domain.PlaylistItem) playlistStats {
+	contentIDs := make(map[domain.ContentID]struct{}, len(items))
+	stats := playlistStats{itemCount: len(items)}
+
+	for _, item := range items {
+		contentIDs[item.ContentID()] = struct{}{}
+
+		createdAt := item.CreatedAt()
+		if createdAt != nil && (stats.lastItemAddedAt == nil || createdAt.After(*stats.lastItemAddedAt)) {
+			stats.lastItemAddedAt = createdAt
+		}
+	}
+	stats.distinctContentCount = len(contentIDs)
+
+	return stats
+}
+
 func convertPlaylistItems(sqlxItems []sqlxPlaylistItem) []domain.PlaylistItemData {
 	result := make([]domain.PlaylistItemData, 0, len(sqlxItems))
 	for _, item := range sqlxItems {
 		result = append(result, &playlistItemData{
-			id:        item.ID,
-			contentID: item.ContentID,
-			createdAt: item.CreatedAt,
+			id:           item.ID,
+			contentID:    item.ContentID,
+			availability: item.Availability,
+			createdAt:    item.CreatedAt,
 		})
 	}
 	return result
 }
 
 type sqlxPlaylist struct {
-	ID        uuid.UUID  `db:"playlist_id"`
-	Name      string     `db:"name"`
-	OwnerID   uuid.UUID  `db:"owner_id"`
-	CreatedAt *time.Time `db:"created_at"`
-	UpdatedAt *time.Time `db:"updated_at"`
+	ID           uuid.UUID  `db:"playlist_id"`
+	Name         string     `db:"name"`
+	OwnerID      uuid.UUID  `db:"owner_id"`
+	CreatedAt    *time.Time `db:"created_at"`
+	UpdatedAt    *time.Time `db:"updated_at"`
+	Version      int        `db:"version"`
+	Discoverable bool       `db:"discoverable"`
 }
 
 type sqlxPlaylistItem struct {
-	ID        uuid.UUID  `db:"playlist_item_id"`
-	ContentID uuid.UUID  `db:"content_id"`
-	CreatedAt *time.Time `db:"created_at"`
+	ID           uuid.UUID  `db:"playlist_item_id"`
+	ContentID    uuid.UUID  `db:"content_id"`
+	Availability int        `db:"availability"`
+	CreatedAt    *time.Time `db:"created_at"`
 }
 
 type playlistData struct {
-	id        uuid.UUID
-	name      string
-	ownerID   uuid.UUID
-	items     []domain.PlaylistItemData
-	createdAt *time.Time
-	updatedAt *time.Time
+	id           uuid.UUID
+	name         string
+	ownerID      uuid.UUID
+	items        []domain.PlaylistItemData
+	createdAt    *time.Time
+	updatedAt    *time.Time
+	version      int
+	discoverable bool
 }
 
 func (p *playlistData) ID() domain.PlaylistID {
@@ -330,10 +458,19 @@ func (p *playlistData) UpdatedAt() *time.Time {
 	return p.updatedAt
 }
 
+func (p *playlistData) Version() int {
+	return p.version
+}
+
+func (p *playlistData) Discoverable() bool {
+	return p.discoverable
+}
+
 type playlistItemData struct {
-	id        uuid.UUID
-	contentID uuid.UUID
-	createdAt *time.Time
+	id           uuid.UUID
+	contentID    uuid.UUID
+	availability int
+	createdAt    *time.Time
 }
 
 func (p *playlistItemData) ID() domain.PlaylistItemID {
@@ -344,6 +481,22 @@ func (p *playlistItemData) ContentID() domain.ContentID {
 	return domain.ContentID(p.contentID)
 }
 
+func (p *playlistItemData) Availability() domain.PlaylistItemAvailability {
+	return domain.PlaylistItemAvailability(p.availability)
+}
+
 func (p *playlistItemData) CreatedAt() *time.Time {
 	return p.createdAt
 }
+
+func uuidsToBinaryUUIDs(ids []uuid.UUID) ([][]byte, error) {
+	result := make([][]byte, 0, len(ids))
+	for _, id := range ids {
+		binaryUUID, err := id.MarshalBinary()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		result = append(result, binaryUUID)
+	}
+	return result, nil
+}
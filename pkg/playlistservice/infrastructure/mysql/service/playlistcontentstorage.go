@@ -0,0 +1,45 @@
+package service
+
+import (
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+func NewPlaylistContentStorage(client mysql.Client) service.PlaylistContentStorage {
+	return &playlistContentStorage{client: client}
+}
+
+type playlistContentStorage struct {
+	client mysql.Client
+}
+
+func (storage *playlistContentStorage) PendingContentIDs(limit int) ([]uuid.UUID, error) {
+	const selectSQL = `SELECT DISTINCT content_id FROM playlist_item WHERE availability = ? LIMIT ?`
+
+	var contentIDs []uuid.UUID
+	err := storage.client.Select(&contentIDs, selectSQL, domain.PlaylistItemPendingVerification, limit)
+	return contentIDs, errors.WithStack(err)
+}
+
+func (storage *playlistContentStorage) ContentIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	selectSQL := `SELECT DISTINCT content_id FROM playlist_item`
+	var args []interface{}
+	if after != nil {
+		binaryUUID, err := after.MarshalBinary()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		selectSQL += ` WHERE content_id > ?`
+		args = append(args, binaryUUID)
+	}
+	selectSQL += ` ORDER BY content_id LIMIT ?`
+	args = append(args, limit)
+
+	var contentIDs []uuid.UUID
+	err := storage.client.Select(&contentIDs, selectSQL, args...)
+	return contentIDs, errors.WithStack(err)
+}
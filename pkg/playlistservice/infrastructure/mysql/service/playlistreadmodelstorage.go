@@ -0,0 +1,271 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+	"playlistservice/pkg/playlistservice/infrastructure/mysql/query"
+)
+
+func NewPlaylistReadModelStorage(client mysql.TransactionalClient) service.PlaylistReadModelStorage {
+	return &playlistReadModelStorage{client: client}
+}
+
+type playlistReadModelStorage struct {
+	client mysql.TransactionalClient
+}
+
+func (storage *playlistReadModelStorage) Project(playlistIDs []uuid.UUID) (err error) {
+	if len(playlistIDs) == 0 {
+		return nil
+	}
+
+	// sorted ids keep order of row locks the same for concurrent projections
+	ids := make([]uuid.UUID, len(playlistIDs))
+	copy(ids, playlistIDs)
+	sort.Slice(ids, func(i, j int) bool {
+		return string(ids[i][:]) < string(ids[j][:])
+	})
+
+	binaryIDs, err := uuidsToBinaryUUIDs(ids)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	transaction, err := storage.client.BeginTransaction()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := transaction.Rollback()
+			if rollbackErr != nil {
+				err = errors.Wrap(err, rollbackErr.Error())
+			}
+			return
+		}
+		err = errors.WithStack(transaction.Commit())
+	}()
+
+	// events are stored before unit of work commits, locking reads wait for it to read committed state
+	playlists, err := lockPlaylists(transaction, binaryIDs)
+	if err != nil {
+		return err
+	}
+
+	items, err := lockPlaylistItems(transaction, binaryIDs)
+	if err != nil {
+		return err
+	}
+
+	projected := make(map[uuid.UUID]bool, len(playlists))
+	for _, playlist := range playlists {
+		err = storeReadModel(transaction, playlist, items[playlist.ID])
+		if err != nil {
+			return err
+		}
+		projected[playlist.ID] = true
+	}
+
+	var absentIDs [][]byte
+	for i, id := range ids {
+		if !projected[id] {
+			absentIDs = append(absentIDs, binaryIDs[i])
+		}
+	}
+	if len(absentIDs) == 0 {
+		return nil
+	}
+
+	sqlQuery, args, err := sqlx.In(`DELETE FROM playlist_read_model WHERE playlist_id IN (?)`, absentIDs)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = transaction.Exec(sqlQuery, args...)
+	return errors.WithStack(err)
+}
+
+func (storage *playlistReadModelStorage) PlaylistIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	return storage.playlistIDs(`SELECT p.playlist_id FROM playlist p`, nil, after, limit)
+}
+
+func (storage *playlistReadModelStorage) UnprojectedPlaylistIDs(after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	return storage.playlistIDs(
+		`SELECT p.playlist_id FROM playlist p LEFT JOIN playlist_read_model rm ON rm.playlist_id = p.playlist_id`,
+		[]string{`rm.playlist_id IS NULL`},
+		after,
+		limit,
+	)
+}
+
+func (storage *playlistReadModelStorage) playlistIDs(selectSQL string, conditions []string, after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var args []interface{}
+	if after != nil {
+		binaryUUID, err := after.MarshalBinary()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		conditions = append(conditions, `p.playlist_id > ?`)
+		args = append(args, binaryUUID)
+	}
+	if len(conditions) != 0 {
+		selectSQL += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	selectSQL += ` ORDER BY p.playlist_id LIMIT ?`
+	args = append(args, limit)
+
+	var playlistIDs []uuid.UUID
+	err := storage.client.Select(&playlistIDs, selectSQL, args...)
+	return playlistIDs, errors.WithStack(err)
+}
+
+func (storage *playlistReadModelStorage) RemoveAbsentPlaylists() (int, error) {
+	const deleteSQL = `
+		DELETE rm FROM playlist_read_model rm
+		LEFT JOIN playlist p ON p.playlist_id = rm.playlist_id
+		WHERE p.playlist_id IS NULL
+	`
+
+	result, err := storage.client.Exec(deleteSQL)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	removed, err := result.RowsAffected()
+	return int(removed), errors.WithStack(err)
+}
+
+func lockPlaylists(transaction mysql.Transaction, binaryIDs [][]byte) ([]sqlxPlaylist, error) {
+	const selectSQL = `
+		SELECT playlist_id, name, owner_id, created_at, updated_at, version, item_count, distinct_content_count, last_item_added_at, discoverable
+		FROM playlist WHERE playlist_id IN (?) ORDER BY playlist_id LOCK IN SHARE MODE
+	`
+
+	sqlQuery, args, err := sqlx.In(selectSQL, binaryIDs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var playlists []sqlxPlaylist
+	err = transaction.Select(&playlists, sqlQuery, args...)
+	return playlists, errors.WithStack(err)
+}
+
+func lockPlaylistItems(transaction mysql.Transaction, binaryIDs [][]byte) (map[uuid.UUID][]query.ReadModelPlaylistItem, error) {
+	const selectSQL = `
+		SELECT playlist_item_id, playlist_id, content_id, availability, created_at, position
+		FROM playlist_item WHERE playlist_id IN (?) ORDER BY position LOCK IN SHARE MODE
+	`
+
+	sqlQuery, args, err := sqlx.In(selectSQL, binaryIDs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var items []sqlxPlaylistItem
+	err = transaction.Select(&items, sqlQuery, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := map[uuid.UUID][]query.ReadModelPlaylistItem{}
+	for _, item := range items {
+		result[item.PlaylistID] = append(result[item.PlaylistID], query.ReadModelPlaylistItem{
+			ID:           item.ID,
+			ContentID:    item.ContentID,
+			Availability: item.Availability,
+			CreatedAt:    item.CreatedAt,
+			Position:     item.Position,
+		})
+	}
+
+	return result, nil
+}
+
+func storeReadModel(transaction mysql.Transaction, playlist sqlxPlaylist, items []query.ReadModelPlaylistItem) error {
+	const insertSQL = `
+		INSERT INTO playlist_read_model (playlist_id, name, owner_id, created_at, updated_at, version, item_count, distinct_content_count, last_item_added_at, discoverable, items)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY
+		UPDATE name=VALUES(name), owner_id=VALUES(owner_id), created_at=VALUES(created_at), updated_at=VALUES(updated_at), version=VALUES(version),
+			item_count=VALUES(item_count), distinct_content_count=VALUES(distinct_content_count), last_item_added_at=VALUES(last_item_added_at),
+			discoverable=VALUES(discoverable), items=VALUES(items)
+	`
+
+	if items == nil {
+		items = []query.ReadModelPlaylistItem{}
+	}
+	serializedItems, err := json.Marshal(items)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	playlistID, err := playlist.ID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	ownerID, err := playlist.OwnerID.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = transaction.Exec(
+		insertSQL,
+		playlistID,
+		playlist.Name,
+		ownerID,
+		playlist.CreatedAt,
+		playlist.UpdatedAt,
+		playlist.Version,
+		playlist.ItemCount,
+		playlist.DistinctContentCount,
+		playlist.LastItemAddedAt,
+		playlist.Discoverable,
+		string(serializedItems),
+	)
+	return errors.WithStack(err)
+}
+
+func uuidsToBinaryUUIDs(uuids []uuid.UUID) ([][]byte, error) {
+	res := make([][]byte, len(uuids))
+	for i, id := range uuids {
+		binaryUUID, err := id.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		res[i] = binaryUUID
+	}
+	return res, nil
+}
+
+type sqlxPlaylist struct {
+	ID                   uuid.UUID  `db:"playlist_id"`
+	Name                 string     `db:"name"`
+	OwnerID              uuid.UUID  `db:"owner_id"`
+	CreatedAt            time.Time  `db:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at"`
+	Version              int        `db:"version"`
+	ItemCount            int        `db:"item_count"`
+	DistinctContentCount int        `db:"distinct_content_count"`
+	LastItemAddedAt      *time.Time `db:"last_item_added_at"`
+	Discoverable         bool       `db:"discoverable"`
+}
+
+type sqlxPlaylistItem struct {
+	ID           uuid.UUID  `db:"playlist_item_id"`
+	PlaylistID   uuid.UUID  `db:"playlist_id"`
+	ContentID    uuid.UUID  `db:"content_id"`
+	Availability int        `db:"availability"`
+	CreatedAt    *time.Time `db:"created_at"`
+	Position     int64      `db:"position"`
+}
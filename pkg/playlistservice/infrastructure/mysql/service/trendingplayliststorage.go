@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+	"playlistservice/pkg/playlistservice/domain"
+)
+
+// rowsPerInsert limits rows inserted by single statement
+const rowsPerInsert = 100
+
+func NewTrendingPlaylistStorage(client mysql.TransactionalClient) service.TrendingPlaylistStorage {
+	return &trendingPlaylistStorage{client: client}
+}
+
+type trendingPlaylistStorage struct {
+	client mysql.TransactionalClient
+}
+
+func (storage *trendingPlaylistStorage) AddReads(reads map[uuid.UUID]int, at time.Time) error {
+	const insertSQL = `
+		INSERT INTO playlist_read_activity (playlist_id, hour_start, read_count) VALUES %s
+		ON DUPLICATE KEY UPDATE read_count = read_count + VALUES(read_count)
+	`
+
+	hourStart := at.Truncate(time.Hour)
+	values := make([]string, 0, len(reads))
+	args := make([]interface{}, 0, len(reads)*3)
+
+	for playlistID, count := range reads {
+		binaryUUID, err := playlistID.MarshalBinary()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		values = append(values, "(?, ?, ?)")
+		args = append(args, binaryUUID, hourStart, count)
+	}
+
+	_, err := storage.client.Exec(fmt.Sprintf(insertSQL, strings.Join(values, ", ")), args...)
+	return errors.WithStack(err)
+}
+
+// DiscoverableActivity joins item additions parsed from stored events with playlists by binary id made of uuid string
+func (storage *trendingPlaylistStorage) DiscoverableActivity(since time.Time) ([]service.PlaylistActivity, error) {
+	const selectSQL = `
+		SELECT a.playlist_id, a.hour_start, a.read_count, 0 AS item_additions
+		FROM playlist_read_activity a
+		INNER JOIN playlist p ON p.playlist_id = a.playlist_id
+		WHERE p.discoverable = 1 AND a.hour_start >= ?
+		UNION ALL
+		SELECT e.playlist_id, e.hour_start, 0 AS read_count, e.item_additions
+		FROM (
+			SELECT
+				UNHEX(REPLACE(JSON_UNQUOTE(JSON_EXTRACT(body, '$.Payload.playlist_id')), '-', '')) AS playlist_id,
+				CAST(DATE_FORMAT(created_at, '%Y-%m-%d %H:00:00') AS DATETIME) AS hour_start,
+				COUNT(*) AS item_additions
+			FROM stored_event
+			WHERE type = ? AND created_at >= ?
+			GROUP BY playlist_id, hour_start
+		) e
+		INNER JOIN playlist p ON p.playlist_id = e.playlist_id
+		WHERE p.discoverable = 1
+	`
+
+	var activity []sqlxPlaylistActivity
+	err := storage.client.Select(&activity, selectSQL, since, domain.PlaylistItemAdded{}.ID(), since)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]service.PlaylistActivity, len(activity))
+	for i, hourActivity := range activity {
+		result[i] = service.PlaylistActivity{
+			PlaylistID:    hourActivity.PlaylistID,
+			Hour:          hourActivity.HourStart,
+			Reads:         hourActivity.ReadCount,
+			ItemAdditions: hourActivity.ItemAdditions,
+		}
+	}
+	return result, nil
+}
+
+func (storage *trendingPlaylistStorage) RemoveReadsBefore(before time.Time) error {
+	const deleteSQL = `DELETE FROM playlist_read_activity WHERE hour_start < ?`
+
+	_, err := storage.client.Exec(deleteSQL, before.Truncate(time.Hour))
+	return errors.WithStack(err)
+}
+
+func (storage *trendingPlaylistStorage) AvailableContentIDs(playlistIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	const selectSQL = `
+		SELECT DISTINCT playlist_id, content_id FROM playlist_item
+		WHERE playlist_id IN (?) AND availability = ?
+	`
+
+	if len(playlistIDs) == 0 {
+		return nil, nil
+	}
+
+	binaryIDs, err := uuidsToBinaryUUIDs(playlistIDs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sqlQuery, args, err := sqlx.In(selectSQL, binaryIDs, domain.PlaylistItemAvailable)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var items []sqlxPlaylistContent
+	err = storage.client.Select(&items, sqlQuery, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make(map[uuid.UUID][]uuid.UUID, len(playlistIDs))
+	for _, item := range items {
+		result[item.PlaylistID] = append(result[item.PlaylistID], item.ContentID)
+	}
+	return result, nil
+}
+
+func (storage *trendingPlaylistStorage) ReplaceRanking(playlists []service.TrendingPlaylist) (err error) {
+	transaction, err := storage.client.BeginTransaction()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := transaction.Rollback()
+			if rollbackErr != nil {
+				err = errors.Wrap(err, rollbackErr.Error())
+			}
+			return
+		}
+		err = errors.WithStack(transaction.Commit())
+	}()
+
+	_, err = transaction.Exec(`DELETE FROM trending_playlist`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for start := 0; start < len(playlists); start += rowsPerInsert {
+		end := start + rowsPerInsert
+		if end > len(playlists) {
+			end = len(playlists)
+		}
+
+		err = insertRanking(transaction, playlists[start:end], start)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertRanking(transaction mysql.Transaction, playlists []service.TrendingPlaylist, offset int) error {
+	const insertSQL = `INSERT INTO trending_playlist (position, playlist_id, score, content_types) VALUES %s`
+
+	values := make([]string, 0, len(playlists))
+	args := make([]interface{}, 0, len(playlists)*4)
+
+	for i, playlist := range playlists {
+		binaryUUID, err := playlist.PlaylistID.MarshalBinary()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		contentTypes := 0
+		for _, contentType := range playlist.ContentTypes {
+			contentTypes |= 1 << uint(contentType)
+		}
+
+		values = append(values, "(?, ?, ?, ?)")
+		// positions start from 1
+		args = append(args, offset+i+1, binaryUUID, playlist.Score, contentTypes)
+	}
+
+	_, err := transaction.Exec(fmt.Sprintf(insertSQL, strings.Join(values, ", ")), args...)
+	return errors.WithStack(err)
+}
+
+type sqlxPlaylistActivity struct {
+	PlaylistID    uuid.UUID `db:"playlist_id"`
+	HourStart     time.Time `db:"hour_start"`
+	ReadCount     int       `db:"read_count"`
+	ItemAdditions int       `db:"item_additions"`
+}
+
+type sqlxPlaylistContent struct {
+	PlaylistID uuid.UUID `db:"playlist_id"`
+	ContentID  uuid.UUID `db:"content_id"`
+}
@@ -0,0 +1,16 @@
+package mysql
+
+import (
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+const trendingRankingLockName = "trending-ranking-lock"
+
+var ErrTrendingRankingLockNotAcquired = errors.New("lock for trending ranking not acquired")
+
+func NewTrendingRankingLock(client mysql.TransactionalClient) service.TrendingRankingLock {
+	return newNamedLock(client, trendingRankingLockName, ErrTrendingRankingLockNotAcquired)
+}
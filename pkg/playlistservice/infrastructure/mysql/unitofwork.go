@@ -45,6 +45,18 @@ func (u *unitOfWork) PlaylistRepository() domain.PlaylistRepository {
 	return repository.NewPlaylistRepository(u.transaction)
 }
 
+func (u *unitOfWork) IdempotencyRecordRepository() service.IdempotencyRecordRepository {
+	return repository.NewIdempotencyRecordRepository(u.transaction)
+}
+
+func (u *unitOfWork) AuditRecordRepository() service.AuditRecordRepository {
+	return repository.NewAuditRecordRepository(u.transaction)
+}
+
+func (u *unitOfWork) DataExportRepository() service.DataExportRepository {
+	return repository.NewDataExportRepository(u.transaction)
+}
+
 func (u *unitOfWork) Complete(err error) error {
 	if u.lock != nil {
 		lockErr := u.lock.Unlock()
@@ -62,6 +74,7 @@ func (u *unitOfWork) Complete(err error) error {
 		if err2 != nil {
 			return errors.Wrap(err, err2.Error())
 		}
+		return err
 	}
 
 	return errors.WithStack(u.transaction.Commit())
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitOfWorkComplete(t *testing.T) {
+	errCommand := errors.New("command failed")
+
+	{
+		transaction := &mockTransaction{}
+		err := (&unitOfWork{transaction: transaction}).Complete(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, transaction.commits)
+		assert.Equal(t, 0, transaction.rollbacks)
+	}
+
+	{
+		transaction := &mockTransaction{}
+		err := (&unitOfWork{transaction: transaction}).Complete(errCommand)
+		assert.Equal(t, errCommand, err)
+		assert.Equal(t, 0, transaction.commits)
+		assert.Equal(t, 1, transaction.rollbacks)
+	}
+
+	{
+		transaction := &mockTransaction{rollbackErr: sql.ErrConnDone}
+		err := (&unitOfWork{transaction: transaction}).Complete(errCommand)
+		assert.Equal(t, errCommand, errors.Cause(err))
+		assert.Contains(t, err.Error(), sql.ErrConnDone.Error())
+		assert.Equal(t, 0, transaction.commits)
+	}
+}
+
+type mockTransaction struct {
+	mysql.Transaction
+	rollbackErr error
+	commits     int
+	rollbacks   int
+}
+
+func (transaction *mockTransaction) Commit() error {
+	transaction.commits++
+	return nil
+}
+
+func (transaction *mockTransaction) Rollback() error {
+	transaction.rollbacks++
+	return transaction.rollbackErr
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+type ContentCacheConfig struct {
+	Enabled bool
+	Size    int
+	TTL     time.Duration
+}
+
+type ContentCacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Size      int   `json:"size"`
+}
+
+type CachedContentChecker interface {
+	service.ContentChecker
+	service.ContentCache
+	Stats() ContentCacheStats
+}
+
+// NewCachedContentChecker remembers content confirmed by checker, so repeated checks don't reach content service
+func NewCachedContentChecker(checker service.ContentChecker, config ContentCacheConfig) CachedContentChecker {
+	return &cachedContentChecker{
+		checker: checker,
+		config:  config,
+		entries: map[uuid.UUID]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+type cacheEntry struct {
+	contentID uuid.UUID
+	expiresAt time.Time
+}
+
+type cachedContentChecker struct {
+	checker service.ContentChecker
+	config  ContentCacheConfig
+
+	mutex   sync.Mutex
+	entries map[uuid.UUID]*list.Element
+	// order keeps least recently used entries at the back
+	order *list.List
+
+	// generation is changed by every invalidation, so checks which started before it do not cache stale result
+	generation uint64
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+func (checker *cachedContentChecker) ContentExists(contentIDs []uuid.UUID) error {
+	missingIDs := checker.missingIDs(contentIDs, time.Now())
+	if len(missingIDs) == 0 {
+		atomic.AddInt64(&checker.hits, 1)
+		return nil
+	}
+
+	atomic.AddInt64(&checker.misses, 1)
+
+	generation := atomic.LoadUint64(&checker.generation)
+
+	err := checker.checker.ContentExists(missingIDs)
+	if err != nil {
+		return err
+	}
+
+	checker.add(missingIDs, generation, time.Now())
+
+	return nil
+}
+
+func (checker *cachedContentChecker) Invalidate(contentIDs []uuid.UUID) {
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+
+	atomic.AddUint64(&checker.generation, 1)
+
+	for _, contentID := range contentIDs {
+		element, ok := checker.entries[contentID]
+		if ok {
+			checker.remove(element)
+		}
+	}
+}
+
+func (checker *cachedContentChecker) Stats() ContentCacheStats {
+	checker.mutex.Lock()
+	size := checker.order.Len()
+	checker.mutex.Unlock()
+
+	return ContentCacheStats{
+		Hits:      atomic.LoadInt64(&checker.hits),
+		Misses:    atomic.LoadInt64(&checker.misses),
+		Evictions: atomic.LoadInt64(&checker.evictions),
+		Size:      size,
+	}
+}
+
+func (checker *cachedContentChecker) missingIDs(contentIDs []uuid.UUID, now time.Time) []uuid.UUID {
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+
+	var result []uuid.UUID
+	for _, contentID := range contentIDs {
+		element, ok := checker.entries[contentID]
+		if !ok {
+			result = append(result, contentID)
+			continue
+		}
+
+		if now.After(element.Value.(*cacheEntry).expiresAt) {
+			checker.remove(element)
+			result = append(result, contentID)
+			continue
+		}
+
+		checker.order.MoveToFront(element)
+	}
+
+	return result
+}
+
+// add skips caching when content was invalidated since check of given generation started
+func (checker *cachedContentChecker) add(contentIDs []uuid.UUID, generation uint64, now time.Time) {
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+
+	if atomic.LoadUint64(&checker.generation) != generation {
+		return
+	}
+
+	for _, contentID := range contentIDs {
+		element, ok := checker.entries[contentID]
+		if ok {
+			element.Value.(*cacheEntry).expiresAt = now.Add(checker.config.TTL)
+			checker.order.MoveToFront(element)
+			continue
+		}
+
+		checker.entries[contentID] = checker.order.PushFront(&cacheEntry{
+			contentID: contentID,
+			expiresAt: now.Add(checker.config.TTL),
+		})
+
+		for checker.order.Len() > checker.config.Size {
+			checker.remove(checker.order.Back())
+			atomic.AddInt64(&checker.evictions, 1)
+		}
+	}
+}
+
+func (checker *cachedContentChecker) remove(element *list.Element) {
+	checker.order.Remove(element)
+	delete(checker.entries, element.Value.(*cacheEntry).contentID)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+func TestCachedContentChecker_ContentExists(t *testing.T) {
+	contentIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	checker := &mockContentChecker{}
+	cache := NewCachedContentChecker(checker, ContentCacheConfig{Enabled: true, Size: 10, TTL: time.Hour})
+
+	assert.NoError(t, cache.ContentExists(contentIDs))
+	assert.Equal(t, [][]uuid.UUID{contentIDs}, checker.requests)
+
+	assert.NoError(t, cache.ContentExists(contentIDs))
+	assert.Len(t, checker.requests, 1, "confirmed content is not checked again")
+
+	newContentID := uuid.New()
+	assert.NoError(t, cache.ContentExists([]uuid.UUID{contentIDs[0], newContentID}))
+	assert.Equal(t, []uuid.UUID{newContentID}, checker.requests[1], "only not cached content is checked")
+
+	checker.err = service.ErrContentNotFound
+	missingContentID := uuid.New()
+	assert.Equal(t, service.ErrContentNotFound, cache.ContentExists([]uuid.UUID{missingContentID}))
+	checker.err = nil
+	assert.NoError(t, cache.ContentExists([]uuid.UUID{missingContentID}))
+	assert.Len(t, checker.requests, 4, "failed check is not cached")
+
+	assert.Equal(t, ContentCacheStats{Hits: 1, Misses: 4, Size: 4}, cache.Stats())
+}
+
+func TestCachedContentChecker_Eviction(t *testing.T) {
+	contentIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	checker := &mockContentChecker{}
+	cache := NewCachedContentChecker(checker, ContentCacheConfig{Enabled: true, Size: 2, TTL: time.Hour})
+
+	assert.NoError(t, cache.ContentExists(contentIDs[:1]))
+	assert.NoError(t, cache.ContentExists(contentIDs[1:2]))
+	assert.NoError(t, cache.ContentExists(contentIDs[:1]), "hit makes content recently used")
+	assert.NoError(t, cache.ContentExists(contentIDs[2:]))
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Evictions)
+	assert.Equal(t, 2, stats.Size)
+
+	checker.requests = nil
+	assert.NoError(t, cache.ContentExists([]uuid.UUID{contentIDs[0], contentIDs[2]}))
+	assert.Empty(t, checker.requests, "recently used content is kept")
+
+	assert.NoError(t, cache.ContentExists(contentIDs[1:2]))
+	assert.Equal(t, [][]uuid.UUID{contentIDs[1:2]}, checker.requests, "least recently used content is evicted")
+}
+
+func TestCachedContentChecker_TTL(t *testing.T) {
+	contentID := uuid.New()
+	cache := NewCachedContentChecker(&mockContentChecker{}, ContentCacheConfig{Enabled: true, Size: 10, TTL: time.Minute}).(*cachedContentChecker)
+
+	now := time.Now()
+	cache.add([]uuid.UUID{contentID}, 0, now)
+
+	assert.Empty(t, cache.missingIDs([]uuid.UUID{contentID}, now.Add(time.Minute)))
+	assert.Equal(t, []uuid.UUID{contentID}, cache.missingIDs([]uuid.UUID{contentID}, now.Add(time.Minute+time.Second)), "expired content is checked again")
+	assert.Equal(t, 0, cache.Stats().Size, "expired content is removed")
+}
+
+func TestCachedContentChecker_Invalidate(t *testing.T) {
+	contentIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	checker := &mockContentChecker{}
+	cache := NewCachedContentChecker(checker, ContentCacheConfig{Enabled: true, Size: 10, TTL: time.Hour})
+
+	assert.NoError(t, cache.ContentExists(contentIDs))
+	cache.Invalidate(contentIDs[:1])
+
+	checker.requests = nil
+	assert.NoError(t, cache.ContentExists(contentIDs))
+	assert.Equal(t, [][]uuid.UUID{contentIDs[:1]}, checker.requests, "invalidated content is checked again")
+
+	{
+		contentID := uuid.New()
+		checker.onCheck = func() {
+			cache.Invalidate([]uuid.UUID{contentID})
+		}
+		assert.NoError(t, cache.ContentExists([]uuid.UUID{contentID}))
+		checker.onCheck = nil
+
+		checker.requests = nil
+		assert.NoError(t, cache.ContentExists([]uuid.UUID{contentID}))
+		assert.Len(t, checker.requests, 1, "content invalidated during check is not cached")
+	}
+}
+
+type mockContentChecker struct {
+	requests [][]uuid.UUID
+	err      error
+	onCheck  func()
+}
+
+func (checker *mockContentChecker) ContentExists(contentIDs []uuid.UUID) error {
+	checker.requests = append(checker.requests, contentIDs)
+	if checker.onCheck != nil {
+		checker.onCheck()
+	}
+	return checker.err
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker opens after failureThreshold consecutive failures and lets single probe call through after openTimeout
+type circuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mutex    sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(failureThreshold int, openTimeout time.Duration) *circuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+	}
+}
+
+func (breaker *circuitBreaker) allow() error {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	switch breaker.state {
+	case circuitOpen:
+		if time.Since(breaker.openedAt) < breaker.openTimeout {
+			return ErrCircuitOpen
+		}
+		breaker.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		// probe call is in flight
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+func (breaker *circuitBreaker) onSuccess() {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.state = circuitClosed
+	breaker.failures = 0
+}
+
+func (breaker *circuitBreaker) onFailure() {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.failures++
+	if breaker.state == circuitHalfOpen || breaker.failures >= breaker.failureThreshold {
+		breaker.state = circuitOpen
+		breaker.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const openTimeout = 20 * time.Millisecond
+	breaker := newCircuitBreaker(2, openTimeout)
+
+	{
+		assert.NoError(t, breaker.allow())
+		breaker.onFailure()
+		breaker.onSuccess()
+		breaker.onFailure()
+		assert.NoError(t, breaker.allow(), "success resets consecutive failures")
+	}
+
+	{
+		breaker.onFailure()
+		assert.Equal(t, ErrCircuitOpen, breaker.allow(), "breaker opens after threshold")
+	}
+
+	{
+		time.Sleep(openTimeout)
+		assert.NoError(t, breaker.allow(), "probe call is allowed after timeout")
+		assert.Equal(t, ErrCircuitOpen, breaker.allow(), "only single probe call is allowed")
+
+		breaker.onFailure()
+		assert.Equal(t, ErrCircuitOpen, breaker.allow(), "failed probe opens breaker again")
+	}
+
+	{
+		time.Sleep(openTimeout)
+		assert.NoError(t, breaker.allow())
+		breaker.onSuccess()
+		assert.NoError(t, breaker.allow(), "successful probe closes breaker")
+		assert.NoError(t, breaker.allow())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 
 	contentserviceapi "playlistservice/api/contentservice"
 	"playlistservice/pkg/playlistservice/app/service"
@@ -23,11 +24,41 @@ func (checker *contentChecker) ContentExists(contentIDs []uuid.UUID) error {
 		ContentIDs: uuidsToStrings(contentIDs),
 	})
 	if err != nil {
+		if err == ErrCircuitOpen || isTransientError(err) {
+			return errors.Wrap(service.ErrContentServiceUnavailable, err.Error())
+		}
 		return err
 	}
 
-	if len(resp.Contents) == 0 {
-		return service.ErrContentNotFound
+	// ids are compared parsed, since content service may format them differently
+	contents := make(map[uuid.UUID]*contentserviceapi.Content, len(resp.Contents))
+	for _, content := range resp.Contents {
+		contentID, err := uuid.Parse(content.ContentID)
+		if err != nil {
+			return errors.Wrapf(err, "content service returned invalid content id %q", content.ContentID)
+		}
+		contents[contentID] = content
+	}
+
+	var missingIDs []uuid.UUID
+	var unavailableIDs []uuid.UUID
+	for _, contentID := range contentIDs {
+		content, ok := contents[contentID]
+		if !ok {
+			missingIDs = append(missingIDs, contentID)
+			continue
+		}
+
+		if content.AvailabilityType != contentserviceapi.ContentAvailabilityType_Public {
+			unavailableIDs = append(unavailableIDs, contentID)
+		}
+	}
+
+	if len(missingIDs) != 0 || len(unavailableIDs) != 0 {
+		return &service.ContentNotFoundError{
+			MissingContentIDs:     missingIDs,
+			UnavailableContentIDs: unavailableIDs,
+		}
 	}
 
 	return nil
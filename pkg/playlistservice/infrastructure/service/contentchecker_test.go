@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	contentserviceapi "playlistservice/api/contentservice"
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+func TestContentChecker_ContentExists(t *testing.T) {
+	publicID, privateID, missingID := uuid.New(), uuid.New(), uuid.New()
+
+	client := &mockContentServiceClient{contents: []*contentserviceapi.Content{
+		{ContentID: strings.ToUpper(publicID.String()), AvailabilityType: contentserviceapi.ContentAvailabilityType_Public},
+		{ContentID: privateID.String(), AvailabilityType: contentserviceapi.ContentAvailabilityType_Private},
+	}}
+	checker := NewContentChecker(client)
+
+	{
+		assert.NoError(t, checker.ContentExists([]uuid.UUID{publicID}), "ids are matched regardless of format")
+	}
+
+	{
+		err := checker.ContentExists([]uuid.UUID{publicID, privateID, missingID})
+		assert.True(t, errors.Is(err, service.ErrContentNotFound))
+
+		var notFoundErr *service.ContentNotFoundError
+		assert.True(t, errors.As(err, &notFoundErr))
+		assert.Equal(t, []uuid.UUID{missingID}, notFoundErr.MissingContentIDs)
+		assert.Equal(t, []uuid.UUID{privateID}, notFoundErr.UnavailableContentIDs)
+	}
+
+	{
+		client.contents = append(client.contents, &contentserviceapi.Content{ContentID: "not uuid"})
+		err := checker.ContentExists([]uuid.UUID{publicID})
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, service.ErrContentNotFound), "invalid response is not reported as missing content")
+	}
+}
+
+type mockContentServiceClient struct {
+	contentserviceapi.ContentServiceClient
+	contents []*contentserviceapi.Content
+	// errs are returned by consecutive calls before contents
+	errs     []error
+	requests []*contentserviceapi.GetContentListRequest
+}
+
+func (client *mockContentServiceClient) GetContentList(_ context.Context, in *contentserviceapi.GetContentListRequest, _ ...grpc.CallOption) (*contentserviceapi.GetContentListResponse, error) {
+	client.requests = append(client.requests, in)
+	if len(client.errs) != 0 {
+		err := client.errs[0]
+		client.errs = client.errs[1:]
+		return nil, err
+	}
+	return &contentserviceapi.GetContentListResponse{Contents: client.contents}, nil
+}
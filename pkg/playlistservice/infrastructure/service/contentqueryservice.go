@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	contentserviceapi "playlistservice/api/contentservice"
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+// contentListBatchSize limits content ids requested from content service at once
+const contentListBatchSize = 100
+
+func NewContentQueryService(contentServiceClient contentserviceapi.ContentServiceClient) query.ContentQueryService {
+	return &contentQueryService{contentServiceClient: contentServiceClient}
+}
+
+type contentQueryService struct {
+	contentServiceClient contentserviceapi.ContentServiceClient
+}
+
+func (service *contentQueryService) GetContents(ctx context.Context, contentIDs []uuid.UUID) ([]query.ContentView, error) {
+	var result []query.ContentView
+	for start := 0; start < len(contentIDs); start += contentListBatchSize {
+		end := start + contentListBatchSize
+		if end > len(contentIDs) {
+			end = len(contentIDs)
+		}
+
+		contents, err := service.getContents(ctx, contentIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, contents...)
+	}
+
+	return result, nil
+}
+
+func (service *contentQueryService) getContents(ctx context.Context, contentIDs []uuid.UUID) ([]query.ContentView, error) {
+	resp, err := service.contentServiceClient.GetContentList(ctx, &contentserviceapi.GetContentListRequest{
+		ContentIDs: uuidsToStrings(contentIDs),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]query.ContentView, 0, len(resp.Contents))
+	for _, content := range resp.Contents {
+		contentID, err := uuid.Parse(content.ContentID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		authorID, err := uuid.Parse(content.AuthorID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		result = append(result, query.ContentView{
+			ID:               contentID,
+			Name:             content.Name,
+			AuthorID:         authorID,
+			Type:             query.ContentType(content.ContentType),
+			AvailabilityType: query.ContentAvailabilityType(content.AvailabilityType),
+		})
+	}
+
+	return result, nil
+}
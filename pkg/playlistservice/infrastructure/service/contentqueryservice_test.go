@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	contentserviceapi "playlistservice/api/contentservice"
+)
+
+func TestContentQueryService_GetContents(t *testing.T) {
+	contentIDs := make([]uuid.UUID, 2*contentListBatchSize+1)
+	contents := make([]*contentserviceapi.Content, len(contentIDs))
+	for i := range contentIDs {
+		contentIDs[i] = uuid.New()
+		contents[i] = &contentserviceapi.Content{ContentID: contentIDs[i].String(), AuthorID: uuid.New().String()}
+	}
+
+	client := &mockContentServiceClient{}
+	queryService := NewContentQueryService(client)
+
+	{
+		result, err := queryService.GetContents(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		assert.Empty(t, client.requests, "content service is not called without ids")
+	}
+
+	{
+		client.contents = contents[:1]
+		result, err := queryService.GetContents(context.Background(), contentIDs)
+		assert.NoError(t, err)
+		assert.Len(t, result, 3, "results of batches are joined")
+
+		assert.Len(t, client.requests, 3)
+		assert.Len(t, client.requests[0].ContentIDs, contentListBatchSize)
+		assert.Len(t, client.requests[1].ContentIDs, contentListBatchSize)
+		assert.Equal(t, []string{contentIDs[len(contentIDs)-1].String()}, client.requests[2].ContentIDs)
+	}
+}
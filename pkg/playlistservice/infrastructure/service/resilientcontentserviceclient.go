@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	contentserviceapi "playlistservice/api/contentservice"
+)
+
+type ResilienceConfig struct {
+	CallTimeout time.Duration
+	// MaxRetries applies only to reads, writes are never retried
+	MaxRetries              int
+	RetryBackoff            time.Duration
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+}
+
+// NewResilientContentServiceClient guards calls to content service with deadlines, retries and circuit breaker
+func NewResilientContentServiceClient(
+	client contentserviceapi.ContentServiceClient,
+	config ResilienceConfig,
+) contentserviceapi.ContentServiceClient {
+	return &resilientContentServiceClient{
+		client:  client,
+		config:  config,
+		breaker: newCircuitBreaker(config.BreakerFailureThreshold, config.BreakerOpenTimeout),
+	}
+}
+
+type resilientContentServiceClient struct {
+	client  contentserviceapi.ContentServiceClient
+	config  ResilienceConfig
+	breaker *circuitBreaker
+}
+
+func (client *resilientContentServiceClient) AddContent(
+	ctx context.Context,
+	in *contentserviceapi.AddContentRequest,
+	opts ...grpc.CallOption,
+) (resp *contentserviceapi.AddContentResponse, err error) {
+	err = client.call(ctx, false, func(ctx context.Context) (err error) {
+		resp, err = client.client.AddContent(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (client *resilientContentServiceClient) GetAuthorContent(
+	ctx context.Context,
+	in *contentserviceapi.GetAuthorContentRequest,
+	opts ...grpc.CallOption,
+) (resp *contentserviceapi.GetAuthorContentResponse, err error) {
+	err = client.call(ctx, true, func(ctx context.Context) (err error) {
+		resp, err = client.client.GetAuthorContent(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (client *resilientContentServiceClient) GetContentList(
+	ctx context.Context,
+	in *contentserviceapi.GetContentListRequest,
+	opts ...grpc.CallOption,
+) (resp *contentserviceapi.GetContentListResponse, err error) {
+	err = client.call(ctx, true, func(ctx context.Context) (err error) {
+		resp, err = client.client.GetContentList(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (client *resilientContentServiceClient) DeleteContent(
+	ctx context.Context,
+	in *contentserviceapi.DeleteContentRequest,
+	opts ...grpc.CallOption,
+) (resp *emptypb.Empty, err error) {
+	err = client.call(ctx, false, func(ctx context.Context) (err error) {
+		resp, err = client.client.DeleteContent(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (client *resilientContentServiceClient) SetContentAvailabilityType(
+	ctx context.Context,
+	in *contentserviceapi.SetContentAvailabilityTypeRequest,
+	opts ...grpc.CallOption,
+) (resp *emptypb.Empty, err error) {
+	err = client.call(ctx, false, func(ctx context.Context) (err error) {
+		resp, err = client.client.SetContentAvailabilityType(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (client *resilientContentServiceClient) call(ctx context.Context, idempotent bool, f func(ctx context.Context) error) error {
+	attempts := 1
+	if idempotent {
+		attempts += client.config.MaxRetries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(client.config.RetryBackoff * time.Duration(1<<uint(attempt-1))):
+			case <-ctx.Done():
+				return err
+			}
+		}
+
+		err = client.breaker.allow()
+		if err != nil {
+			return err
+		}
+
+		err = client.callWithTimeout(ctx, f)
+		if !isTransientError(err) {
+			client.breaker.onSuccess()
+			return err
+		}
+
+		client.breaker.onFailure()
+	}
+
+	return err
+}
+
+func (client *resilientContentServiceClient) callWithTimeout(ctx context.Context, f func(ctx context.Context) error) error {
+	if client.config.CallTimeout <= 0 {
+		return f(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, client.config.CallTimeout)
+	defer cancel()
+
+	return f(ctx)
+}
+
+// isTransientError reports errors caused by content service health rather than by request itself
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
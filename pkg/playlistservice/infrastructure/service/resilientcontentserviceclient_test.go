@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	contentserviceapi "playlistservice/api/contentservice"
+)
+
+func TestResilientContentServiceClient_Retries(t *testing.T) {
+	unavailableErr := status.Error(codes.Unavailable, "unavailable")
+	config := ResilienceConfig{
+		MaxRetries:              2,
+		RetryBackoff:            time.Millisecond,
+		BreakerFailureThreshold: 10,
+		BreakerOpenTimeout:      time.Hour,
+	}
+
+	{
+		client := &mockContentServiceClient{errs: []error{unavailableErr, unavailableErr}}
+		_, err := NewResilientContentServiceClient(client, config).GetContentList(context.Background(), &contentserviceapi.GetContentListRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, client.requests, 3, "transient errors of reads are retried")
+	}
+
+	{
+		client := &mockContentServiceClient{errs: []error{unavailableErr, unavailableErr, unavailableErr, unavailableErr}}
+		_, err := NewResilientContentServiceClient(client, config).GetContentList(context.Background(), &contentserviceapi.GetContentListRequest{})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Len(t, client.requests, 3, "retries are limited")
+	}
+
+	{
+		client := &mockContentServiceClient{errs: []error{status.Error(codes.InvalidArgument, "invalid"), unavailableErr}}
+		_, err := NewResilientContentServiceClient(client, config).GetContentList(context.Background(), &contentserviceapi.GetContentListRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Len(t, client.requests, 1, "request errors are not retried")
+	}
+
+	{
+		calls := 0
+		client := NewResilientContentServiceClient(&mockContentServiceClient{}, config).(*resilientContentServiceClient)
+		err := client.call(context.Background(), false, func(context.Context) error {
+			calls++
+			return unavailableErr
+		})
+		assert.Equal(t, unavailableErr, err)
+		assert.Equal(t, 1, calls, "writes are not retried")
+	}
+
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		client := &mockContentServiceClient{errs: []error{unavailableErr, unavailableErr}}
+		_, err := NewResilientContentServiceClient(client, ResilienceConfig{MaxRetries: 2, RetryBackoff: time.Hour}).GetContentList(ctx, &contentserviceapi.GetContentListRequest{})
+		assert.Equal(t, unavailableErr, err)
+		assert.Len(t, client.requests, 1, "retries stop when context is done")
+	}
+}
+
+func TestResilientContentServiceClient_CircuitBreaker(t *testing.T) {
+	unavailableErr := status.Error(codes.Unavailable, "unavailable")
+	client := &mockContentServiceClient{errs: []error{unavailableErr, unavailableErr}}
+	resilientClient := NewResilientContentServiceClient(client, ResilienceConfig{
+		BreakerFailureThreshold: 2,
+		BreakerOpenTimeout:      time.Hour,
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := resilientClient.GetContentList(context.Background(), &contentserviceapi.GetContentListRequest{})
+		assert.Equal(t, unavailableErr, err)
+	}
+
+	_, err := resilientClient.GetContentList(context.Background(), &contentserviceapi.GetContentListRequest{})
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Len(t, client.requests, 2, "open breaker does not call content service")
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "no error", err: nil, expected: false},
+		{name: "unavailable", err: status.Error(codes.Unavailable, ""), expected: true},
+		{name: "deadline exceeded", err: status.Error(codes.DeadlineExceeded, ""), expected: true},
+		{name: "resource exhausted", err: status.Error(codes.ResourceExhausted, ""), expected: true},
+		{name: "aborted", err: status.Error(codes.Aborted, ""), expected: true},
+		{name: "invalid argument", err: status.Error(codes.InvalidArgument, ""), expected: false},
+		{name: "not found", err: status.Error(codes.NotFound, ""), expected: false},
+		{name: "internal", err: status.Error(codes.Internal, ""), expected: false},
+		{name: "not status error", err: errors.New("failed"), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isTransientError(test.err))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"context"
+
+	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
+	"github.com/google/uuid"
+
+	api "playlistservice/api/playlistservice"
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+// expandContent embeds content details into items, items with unknown content are marked as unresolved.
+// It returns true when content service failed, so items are unresolved regardless of their content
+func expandContent(
+	ctx context.Context,
+	contentQueryService query.ContentQueryService,
+	logger log.Logger,
+	items []*api.PlaylistItem,
+) (partial bool) {
+	if len(items) == 0 {
+		return false
+	}
+
+	contentIDs := make([]uuid.UUID, 0, len(items))
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.ContentID]; ok {
+			continue
+		}
+		seen[item.ContentID] = struct{}{}
+
+		contentID, err := uuid.Parse(item.ContentID)
+		if err != nil {
+			continue
+		}
+		contentIDs = append(contentIDs, contentID)
+	}
+
+	contents, err := contentQueryService.GetContents(ctx, contentIDs)
+	if err != nil {
+		// playlist stays readable when content service is unavailable
+		logger.Error(err, "failed to expand content of playlist items")
+		contents = nil
+		partial = true
+	}
+
+	contentsMap := make(map[string]query.ContentView, len(contents))
+	for _, content := range contents {
+		contentsMap[content.ID.String()] = content
+	}
+
+	for _, item := range items {
+		content, ok := contentsMap[item.ContentID]
+		if !ok {
+			item.ContentUnresolved = true
+			continue
+		}
+
+		item.Content = &api.Content{
+			Name:             content.Name,
+			AuthorID:         content.AuthorID.String(),
+			Type:             api.ContentType(content.Type),
+			AvailabilityType: api.ContentAvailabilityType(content.AvailabilityType),
+		}
+	}
+
+	return partial
+}
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	api "playlistservice/api/playlistservice"
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+func TestExpandContent(t *testing.T) {
+	knownID, unknownID := uuid.New(), uuid.New()
+	newItems := func() []*api.PlaylistItem {
+		return []*api.PlaylistItem{{ContentID: knownID.String()}, {ContentID: unknownID.String()}, {ContentID: knownID.String()}}
+	}
+
+	{
+		queryService := &mockContentQueryService{contents: []query.ContentView{{ID: knownID, Name: "song"}}}
+		logger := &mockLogger{}
+		items := newItems()
+
+		assert.False(t, expandContent(context.Background(), queryService, logger, items))
+		assert.Equal(t, [][]uuid.UUID{{knownID, unknownID}}, queryService.requests, "content is requested once")
+		assert.Equal(t, "song", items[0].Content.Name)
+		assert.Equal(t, "song", items[2].Content.Name)
+		assert.True(t, items[1].ContentUnresolved)
+		assert.Empty(t, logger.errs)
+	}
+
+	{
+		queryService := &mockContentQueryService{err: errors.New("content service is unavailable")}
+		logger := &mockLogger{}
+		items := newItems()
+
+		assert.True(t, expandContent(context.Background(), queryService, logger, items), "response is partial when content service failed")
+		for _, item := range items {
+			assert.True(t, item.ContentUnresolved)
+		}
+		assert.Equal(t, []error{queryService.err}, logger.errs)
+	}
+}
+
+type mockContentQueryService struct {
+	contents []query.ContentView
+	err      error
+	requests [][]uuid.UUID
+}
+
+func (service *mockContentQueryService) GetContents(_ context.Context, contentIDs []uuid.UUID) ([]query.ContentView, error) {
+	service.requests = append(service.requests, contentIDs)
+	return service.contents, service.err
+}
+
+type mockLogger struct {
+	errs []error
+}
+
+func (logger *mockLogger) WithField(string, interface{}) log.Logger {
+	return logger
+}
+
+func (logger *mockLogger) WithFields(log.Fields) log.Logger {
+	return logger
+}
+
+func (logger *mockLogger) Info(...interface{}) {}
+
+func (logger *mockLogger) Error(err error, _ ...interface{}) {
+	logger.errs = append(logger.errs, err)
+}
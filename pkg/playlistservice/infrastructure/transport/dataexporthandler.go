@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"playlistservice/pkg/playlistservice/app/service"
+	"playlistservice/pkg/playlistservice/infrastructure"
+)
+
+const (
+	DataExportDownloadPath = "/data-exports/{exportID}/download"
+
+	dataExportIDVar          = "exportID"
+	dataExportUserTokenParam = "user_token"
+)
+
+// NewDataExportDownloadHandler serves archive of completed export to user who requested it,
+// user token is passed in query like in other REST requests
+func NewDataExportDownloadHandler(container infrastructure.DependencyContainer, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		exportID, err := uuid.Parse(mux.Vars(request)[dataExportIDVar])
+		if err != nil {
+			http.Error(writer, "invalid export id", http.StatusBadRequest)
+			return
+		}
+
+		userDesc, err := container.UserDescriptorSerializer().Deserialize(request.URL.Query().Get(dataExportUserTokenParam))
+		if err != nil {
+			http.Error(writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		archive, err := container.DataExportService().GetDataExportArchive(exportID, userDesc)
+		if err != nil {
+			switch errors.Cause(err) {
+			case service.ErrDataExportNotFound:
+				http.Error(writer, err.Error(), http.StatusNotFound)
+			case service.ErrDataExportNotCompleted:
+				http.Error(writer, err.Error(), http.StatusConflict)
+			default:
+				logger.Error(err, "failed to get data export archive")
+				http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/zip")
+		writer.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+		writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="playlists-export-%s.zip"`, exportID))
+		_, _ = writer.Write(archive)
+	})
+}
+
+func dataExportDownloadURL(exportID uuid.UUID) string {
+	return fmt.Sprintf("/data-exports/%s/download", exportID)
+}
@@ -1,7 +1,11 @@
 package transport
 
 import (
+	"fmt"
+
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -10,15 +14,86 @@ import (
 )
 
 func translateError(err error) error {
+	var contentNotFoundErr *service.ContentNotFoundError
+	if errors.As(err, &contentNotFoundErr) {
+		return contentNotFoundStatus(contentNotFoundErr)
+	}
+
 	switch errors.Cause(err) {
-	case service.ErrContentNotFound:
+	case service.ErrContentNotFound, service.ErrIdempotencyKeyTooLong:
 		return status.Error(codes.InvalidArgument, err.Error())
+	case service.ErrIdempotencyKeyReused:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case service.ErrContentServiceUnavailable:
+		return status.Error(codes.Unavailable, err.Error())
 	case domain.ErrPlaylistItemNotFound:
-	case domain.ErrPlaylistNotFound:
+	case domain.ErrPlaylistNotFound, service.ErrDataExportNotFound:
 		return status.Error(codes.NotFound, err.Error())
-	case domain.ErrOnlyOwnerCanManagePlaylist:
+	case service.ErrDataExportNotCompleted:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case service.ErrPlaylistVersionMismatch:
+		return playlistVersionMismatchStatus(err)
+	}
+
+	if errors.Is(err, domain.ErrAccessDenied) {
 		return status.Error(codes.PermissionDenied, err.Error())
 	}
 
 	return err
 }
+
+func contentNotFoundStatus(err *service.ContentNotFoundError) error {
+	badRequest := &errdetails.BadRequest{}
+	appendViolations := func(contentIDs []uuid.UUID, description string) {
+		for _, contentID := range contentIDs {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("content_id[%s]", contentID),
+				Description: description,
+			})
+		}
+	}
+	appendViolations(err.MissingContentIDs, "content not found")
+	appendViolations(err.UnavailableContentIDs, "content is not available")
+
+	s, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return s.Err()
+}
+
+func playlistVersionMismatchStatus(err error) error {
+	preconditionFailure := &errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        preconditionFailureType,
+			Subject:     "If-Match",
+			Description: "playlist changed since given version",
+		}},
+	}
+
+	s, detailsErr := status.New(codes.FailedPrecondition, err.Error()).WithDetails(preconditionFailure)
+	if detailsErr != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.Err()
+}
+
+func isETagPreconditionFailure(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() != codes.FailedPrecondition {
+		return false
+	}
+
+	for _, detail := range s.Details() {
+		preconditionFailure, ok := detail.(*errdetails.PreconditionFailure)
+		if !ok {
+			continue
+		}
+		for _, violation := range preconditionFailure.Violations {
+			if violation.Type == preconditionFailureType {
+				return true
+			}
+		}
+	}
+	return false
+}
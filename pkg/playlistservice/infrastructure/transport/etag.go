@@ -0,0 +1,144 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+const (
+	ETagMetadataKey         = "etag"
+	LastModifiedMetadataKey = "last-modified"
+	// NotModifiedMetadataKey marks empty response of conditional read when playlist didn't change
+	NotModifiedMetadataKey = "x-not-modified"
+
+	ifMatchMetadataKey         = "if-match"
+	ifNoneMatchMetadataKey     = "if-none-match"
+	ifModifiedSinceMetadataKey = "if-modified-since"
+	// grpc-gateway forwards conditional http headers with prefix
+	gatewayMetadataKeyPrefix = "grpcgateway-"
+
+	// preconditionFailureType is type of precondition violation for mismatched If-Match
+	preconditionFailureType = "ETAG"
+)
+
+// playlistETag is strong entity tag of playlist version, e.g. "3"
+func playlistETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// setPlaylistVersionHeader sends ETag and Last-Modified of playlist in response header metadata
+func setPlaylistVersionHeader(ctx context.Context, playlist query.PlaylistView) error {
+	return grpc.SetHeader(ctx, metadata.Pairs(
+		ETagMetadataKey, playlistETag(playlist.Version),
+		LastModifiedMetadataKey, playlist.UpdatedAt.UTC().Format(http.TimeFormat),
+	))
+}
+
+// playlistNotModified checks If-None-Match and If-Modified-Since of request, If-Modified-Since is ignored when If-None-Match given
+func playlistNotModified(ctx context.Context, playlist query.PlaylistView) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	ifNoneMatch := conditionalMetadataValue(md, ifNoneMatchMetadataKey)
+	if ifNoneMatch != "" {
+		etag := playlistETag(playlist.Version)
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			// If-None-Match uses weak comparison
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince := conditionalMetadataValue(md, ifModifiedSinceMetadataKey)
+	if ifModifiedSince == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	// http dates have second precision
+	return !playlist.UpdatedAt.Truncate(time.Second).After(since)
+}
+
+// setNotModifiedHeader marks empty response so gateway replies with 304 Not Modified
+func setNotModifiedHeader(ctx context.Context) error {
+	return grpc.SetHeader(ctx, metadata.Pairs(NotModifiedMetadataKey, "true"))
+}
+
+// expectedPlaylistVersion parses If-Match of request, missing header and * return zero which skips the check
+func expectedPlaylistVersion(md metadata.MD) (int, error) {
+	ifMatch := strings.TrimSpace(conditionalMetadataValue(md, ifMatchMetadataKey))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+
+	unquoted, err := strconv.Unquote(ifMatch)
+	if err != nil || !strings.HasPrefix(ifMatch, `"`) {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid If-Match %s, expected single strong entity tag", ifMatch)
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid If-Match %s, expected single strong entity tag", ifMatch)
+	}
+	return version, nil
+}
+
+func conditionalMetadataValue(md metadata.MD, key string) string {
+	value := firstMetadataValue(md, key)
+	if value == "" {
+		value = firstMetadataValue(md, gatewayMetadataKeyPrefix+key)
+	}
+	return value
+}
+
+// GatewayNotModifiedResponseOption replies with 304 Not Modified to responses marked by NotModifiedMetadataKey
+func GatewayNotModifiedResponseOption(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+	md, ok := runtime.ServerMetadataFromContext(ctx)
+	if !ok || len(md.HeaderMD.Get(NotModifiedMetadataKey)) == 0 {
+		return nil
+	}
+
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return nil
+}
+
+// GatewayProtoErrorHandler replies with 412 Precondition Failed to mismatched If-Match instead of 400 of FailedPrecondition
+func GatewayProtoErrorHandler(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	marshaler runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	if isETagPreconditionFailure(err) {
+		w = &statusOverrideResponseWriter{ResponseWriter: w, status: http.StatusPreconditionFailed}
+	}
+	runtime.DefaultHTTPProtoErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+type statusOverrideResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusOverrideResponseWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(w.status)
+}
@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestExpectedPlaylistVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		expected int
+		err      bool
+	}{
+		{
+			name:     "no header skips check",
+			md:       metadata.MD{},
+			expected: 0,
+		},
+		{
+			name:     "any version skips check",
+			md:       metadata.Pairs(ifMatchMetadataKey, "*"),
+			expected: 0,
+		},
+		{
+			name:     "strong entity tag",
+			md:       metadata.Pairs(ifMatchMetadataKey, `"3"`),
+			expected: 3,
+		},
+		{
+			name:     "surrounding spaces",
+			md:       metadata.Pairs(ifMatchMetadataKey, ` "3" `),
+			expected: 3,
+		},
+		{
+			name:     "header forwarded by gateway",
+			md:       metadata.Pairs(gatewayMetadataKeyPrefix+ifMatchMetadataKey, `"7"`),
+			expected: 7,
+		},
+		{
+			name: "weak entity tag",
+			md:   metadata.Pairs(ifMatchMetadataKey, `W/"3"`),
+			err:  true,
+		},
+		{
+			name: "unquoted version",
+			md:   metadata.Pairs(ifMatchMetadataKey, "3"),
+			err:  true,
+		},
+		{
+			name: "several entity tags",
+			md:   metadata.Pairs(ifMatchMetadataKey, `"3", "4"`),
+			err:  true,
+		},
+		{
+			name: "not a number",
+			md:   metadata.Pairs(ifMatchMetadataKey, `"abc"`),
+			err:  true,
+		},
+		{
+			name: "zero version",
+			md:   metadata.Pairs(ifMatchMetadataKey, `"0"`),
+			err:  true,
+		},
+		{
+			name: "negative version",
+			md:   metadata.Pairs(ifMatchMetadataKey, `"-1"`),
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			version, err := expectedPlaylistVersion(test.md)
+			if test.err {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package transport
+
+import (
+	"strings"
+	"unicode"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	api "playlistservice/api/playlistservice"
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+const playlistItemsField = "playlist_items"
+
+var (
+	// playlistFields are fields of Playlist and GetPlaylistResponse which can be selected by field mask
+	playlistFields = []string{
+		"playlist_id",
+		"name",
+		"owner_id",
+		"created_at_timestamp",
+		"updated_at_timestamp",
+		"item_count",
+		"distinct_content_count",
+		"last_item_added_at_timestamp",
+		playlistItemsField,
+	}
+	playlistItemFields = []string{
+		"playlist_item_id",
+		"content_id",
+		"pending_verification",
+		"unavailable",
+		"created_at_timestamp",
+		"content",
+		"content_unresolved",
+	}
+)
+
+// playlistFieldMask keeps fields of playlist requested by field mask, nil mask keeps all fields
+type playlistFieldMask struct {
+	fields map[string]bool
+	// itemFields are requested subfields of playlist_items, nil keeps all fields of requested items
+	itemFields map[string]bool
+}
+
+// parsePlaylistFieldMask accepts paths in proto and json names, empty mask keeps all fields
+func parsePlaylistFieldMask(mask *fieldmaskpb.FieldMask) (*playlistFieldMask, error) {
+	if mask == nil || len(mask.Paths) == 0 {
+		return nil, nil
+	}
+
+	result := &playlistFieldMask{fields: map[string]bool{}}
+	for _, path := range mask.Paths {
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			parts[i] = toSnakeCase(part)
+		}
+
+		switch {
+		case len(parts) == 1 && containsField(playlistFields, parts[0]):
+			result.fields[parts[0]] = true
+		case len(parts) == 2 && parts[0] == playlistItemsField && containsField(playlistItemFields, parts[1]):
+			if result.itemFields == nil {
+				result.itemFields = map[string]bool{}
+			}
+			result.itemFields[parts[1]] = true
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown field %s in field mask", path)
+		}
+	}
+
+	// whole playlist_items path overrides its subpaths
+	if result.fields[playlistItemsField] {
+		result.itemFields = nil
+	} else if result.itemFields != nil {
+		result.fields[playlistItemsField] = true
+	}
+
+	return result, nil
+}
+
+func (mask *playlistFieldMask) includes(field string) bool {
+	return mask == nil || mask.fields[field]
+}
+
+func (mask *playlistFieldMask) includesItemField(field string) bool {
+	if !mask.includes(playlistItemsField) {
+		return false
+	}
+	return mask == nil || mask.itemFields == nil || mask.itemFields[field]
+}
+
+// itemsLimit skips loading of items when they are not requested
+func (mask *playlistFieldMask) itemsLimit() int {
+	if !mask.includes(playlistItemsField) {
+		return query.NoItems
+	}
+	return playlistSummaryItemsCount
+}
+
+// expandsContent skips requests to content service when content of items is not requested
+func (mask *playlistFieldMask) expandsContent(expandContent bool) bool {
+	return expandContent && mask.includesItemField("content")
+}
+
+func (mask *playlistFieldMask) applyToPlaylist(playlist *api.Playlist) {
+	if mask == nil {
+		return
+	}
+
+	if !mask.includes("playlist_id") {
+		playlist.PlaylistID = ""
+	}
+	if !mask.includes("name") {
+		playlist.Name = ""
+	}
+	if !mask.includes("owner_id") {
+		playlist.OwnerID = ""
+	}
+	if !mask.includes("created_at_timestamp") {
+		playlist.CreatedAtTimestamp = 0
+	}
+	if !mask.includes("updated_at_timestamp") {
+		playlist.UpdatedAtTimestamp = 0
+	}
+	if !mask.includes("item_count") {
+		playlist.ItemCount = 0
+	}
+	if !mask.includes("distinct_content_count") {
+		playlist.DistinctContentCount = 0
+	}
+	if !mask.includes("last_item_added_at_timestamp") {
+		playlist.LastItemAddedAtTimestamp = 0
+	}
+	playlist.PlaylistItems = mask.applyToItems(playlist.PlaylistItems)
+}
+
+func (mask *playlistFieldMask) applyToGetPlaylistResponse(resp *api.GetPlaylistResponse) {
+	if mask == nil {
+		return
+	}
+
+	if !mask.includes("name") {
+		resp.Name = ""
+	}
+	if !mask.includes("owner_id") {
+		resp.OwnerID = ""
+	}
+	if !mask.includes("created_at_timestamp") {
+		resp.CreatedAtTimestamp = 0
+	}
+	if !mask.includes("updated_at_timestamp") {
+		resp.UpdatedAtTimestamp = 0
+	}
+	if !mask.includes("item_count") {
+		resp.ItemCount = 0
+	}
+	if !mask.includes("distinct_content_count") {
+		resp.DistinctContentCount = 0
+	}
+	if !mask.includes("last_item_added_at_timestamp") {
+		resp.LastItemAddedAtTimestamp = 0
+	}
+	resp.PlaylistItems = mask.applyToItems(resp.PlaylistItems)
+}
+
+func (mask *playlistFieldMask) applyToItems(items []*api.PlaylistItem) []*api.PlaylistItem {
+	if !mask.includes(playlistItemsField) {
+		return nil
+	}
+	if mask == nil || mask.itemFields == nil {
+		return items
+	}
+
+	for _, item := range items {
+		if !mask.itemFields["playlist_item_id"] {
+			item.PlaylistItemID = ""
+		}
+		if !mask.itemFields["content_id"] {
+			item.ContentID = ""
+		}
+		if !mask.itemFields["pending_verification"] {
+			item.PendingVerification = false
+		}
+		if !mask.itemFields["unavailable"] {
+			item.Unavailable = false
+		}
+		if !mask.itemFields["created_at_timestamp"] {
+			item.CreatedAtTimestamp = 0
+		}
+		if !mask.itemFields["content"] {
+			item.Content = nil
+		}
+		if !mask.itemFields["content_unresolved"] {
+			item.ContentUnresolved = false
+		}
+	}
+	return items
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// toSnakeCase converts json name of field like itemCount to proto name item_count
+func toSnakeCase(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
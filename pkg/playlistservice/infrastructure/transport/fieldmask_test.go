@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestParsePlaylistFieldMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		mask     *fieldmaskpb.FieldMask
+		expected *playlistFieldMask
+		err      bool
+	}{
+		{
+			name:     "no mask keeps all fields",
+			mask:     nil,
+			expected: nil,
+		},
+		{
+			name:     "empty mask keeps all fields",
+			mask:     &fieldmaskpb.FieldMask{},
+			expected: nil,
+		},
+		{
+			name:     "proto names",
+			mask:     &fieldmaskpb.FieldMask{Paths: []string{"name", "item_count"}},
+			expected: &playlistFieldMask{fields: map[string]bool{"name": true, "item_count": true}},
+		},
+		{
+			name:     "json names",
+			mask:     &fieldmaskpb.FieldMask{Paths: []string{"ownerId", "lastItemAddedAtTimestamp"}},
+			expected: &playlistFieldMask{fields: map[string]bool{"owner_id": true, "last_item_added_at_timestamp": true}},
+		},
+		{
+			name:     "whole items",
+			mask:     &fieldmaskpb.FieldMask{Paths: []string{"playlistItems"}},
+			expected: &playlistFieldMask{fields: map[string]bool{"playlist_items": true}},
+		},
+		{
+			name: "item subfields select items",
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"playlist_items.content_id", "playlistItems.createdAtTimestamp"}},
+			expected: &playlistFieldMask{
+				fields:     map[string]bool{"playlist_items": true},
+				itemFields: map[string]bool{"content_id": true, "created_at_timestamp": true},
+			},
+		},
+		{
+			name:     "whole items override subfields",
+			mask:     &fieldmaskpb.FieldMask{Paths: []string{"playlist_items.content_id", "playlist_items"}},
+			expected: &playlistFieldMask{fields: map[string]bool{"playlist_items": true}},
+		},
+		{
+			name: "unknown field",
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"name", "rating"}},
+			err:  true,
+		},
+		{
+			name: "unknown item field",
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"playlist_items.rating"}},
+			err:  true,
+		},
+		{
+			name: "subfield of scalar field",
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"name.content_id"}},
+			err:  true,
+		},
+		{
+			name: "too deep path",
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"playlist_items.content.name"}},
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mask, err := parsePlaylistFieldMask(test.mask)
+			if test.err {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				assert.Nil(t, mask)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, mask)
+		})
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "", expected: ""},
+		{name: "name", expected: "name"},
+		{name: "item_count", expected: "item_count"},
+		{name: "itemCount", expected: "item_count"},
+		{name: "lastItemAddedAtTimestamp", expected: "last_item_added_at_timestamp"},
+		{name: "ItemCount", expected: "item_count"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, toSnakeCase(test.name), test.name)
+	}
+}
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"playlistservice/pkg/playlistservice/app/service"
+)
+
+const (
+	requestIDMetadataKey        = "x-request-id"
+	forwardedForMetadataKey     = "x-forwarded-for"
+	userAgentMetadataKey        = "user-agent"
+	gatewayUserAgentMetadataKey = "grpcgateway-user-agent"
+)
+
+func commandMetadata(ctx context.Context, idempotencyKey string) (service.CommandMetadata, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	userAgent := firstMetadataValue(md, gatewayUserAgentMetadataKey)
+	if userAgent == "" {
+		userAgent = firstMetadataValue(md, userAgentMetadataKey)
+	}
+
+	expectedVersion, err := expectedPlaylistVersion(md)
+	if err != nil {
+		return service.CommandMetadata{}, err
+	}
+
+	return service.CommandMetadata{
+		IdempotencyKey:          idempotencyKey,
+		RequestID:               firstMetadataValue(md, requestIDMetadataKey),
+		SourceIP:                sourceIP(ctx, md),
+		UserAgent:               userAgent,
+		ExpectedPlaylistVersion: expectedVersion,
+	}, nil
+}
+
+// sourceIP returns address of direct peer, for calls proxied by grpc-gateway from loopback address
+// it returns last x-forwarded-for hop which is appended by gateway, hops sent by client are not trusted
+func sourceIP(ctx context.Context, md metadata.MD) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	peerIP := net.ParseIP(host)
+	if peerIP == nil || !peerIP.IsLoopback() {
+		return host
+	}
+
+	forwardedFor := md.Get(forwardedForMetadataKey)
+	if len(forwardedFor) == 0 {
+		return host
+	}
+	hops := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+	return strings.TrimSpace(hops[len(hops)-1])
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestSourceIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		peerAddr     net.Addr
+		forwardedFor []string
+		expected     string
+	}{
+		{
+			name:     "direct peer",
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 5000},
+			expected: "203.0.113.7",
+		},
+		{
+			name:         "forwarded for from remote peer is not trusted",
+			peerAddr:     &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 5000},
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "203.0.113.7",
+		},
+		{
+			name:         "gateway hop",
+			peerAddr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000},
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "198.51.100.1",
+		},
+		{
+			name:         "hops sent by client before gateway are skipped",
+			peerAddr:     &net.TCPAddr{IP: net.ParseIP("::1"), Port: 5000},
+			forwardedFor: []string{"10.0.0.1, 198.51.100.1"},
+			expected:     "198.51.100.1",
+		},
+		{
+			name:     "gateway without forwarded for",
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000},
+			expected: "127.0.0.1",
+		},
+		{
+			name:         "no peer",
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.peerAddr != nil {
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: test.peerAddr})
+			}
+
+			md := metadata.MD{}
+			for _, value := range test.forwardedFor {
+				md.Append(forwardedForMetadataKey, value)
+			}
+
+			assert.Equal(t, test.expected, sourceIP(ctx, md))
+		})
+	}
+}
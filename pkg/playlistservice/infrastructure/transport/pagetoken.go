@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "playlistservice/api/playlistservice"
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+var errInvalidPageToken = status.Errorf(codes.InvalidArgument, "invalid page token")
+
+// playlistPageToken keeps sort with cursor to reject token issued for other ordering
+type playlistPageToken struct {
+	SortField  query.PlaylistSortField `json:"sf"`
+	Descending bool                    `json:"sd"`
+	ID         uuid.UUID               `json:"id"`
+	Name       string                  `json:"n"`
+	CreatedAt  int64                   `json:"c"`
+	UpdatedAt  int64                   `json:"u"`
+	ItemCount  int                     `json:"ic"`
+}
+
+func encodePlaylistPageToken(sort query.PlaylistSort, cursor query.PlaylistCursor) (string, error) {
+	return encodePageToken(playlistPageToken{
+		SortField:  sort.Field,
+		Descending: sort.Descending,
+		ID:         cursor.ID,
+		Name:       cursor.Name,
+		CreatedAt:  cursor.CreatedAt.Unix(),
+		UpdatedAt:  cursor.UpdatedAt.Unix(),
+		ItemCount:  cursor.ItemCount,
+	})
+}
+
+func decodePlaylistPageToken(sort query.PlaylistSort, pageToken string) (*query.PlaylistCursor, error) {
+	if pageToken == "" {
+		return nil, nil
+	}
+
+	var token playlistPageToken
+	err := decodePageToken(pageToken, &token)
+	if err != nil || token.SortField != sort.Field || token.Descending != sort.Descending {
+		return nil, errInvalidPageToken
+	}
+
+	return &query.PlaylistCursor{
+		ID:        token.ID,
+		Name:      token.Name,
+		CreatedAt: time.Unix(token.CreatedAt, 0).UTC(),
+		UpdatedAt: time.Unix(token.UpdatedAt, 0).UTC(),
+		ItemCount: token.ItemCount,
+	}, nil
+}
+
+type playlistItemPageToken struct {
+	SortField  query.PlaylistItemSortField `json:"sf"`
+	Descending bool                        `json:"sd"`
+	ID         uuid.UUID                   `json:"id"`
+	Position   int64                       `json:"p"`
+	CreatedAt  int64                       `json:"c"`
+}
+
+func encodePlaylistItemPageToken(sort query.PlaylistItemSort, cursor query.PlaylistItemCursor) (string, error) {
+	return encodePageToken(playlistItemPageToken{
+		SortField:  sort.Field,
+		Descending: sort.Descending,
+		ID:         cursor.ID,
+		Position:   cursor.Position,
+		CreatedAt:  cursor.CreatedAt.Unix(),
+	})
+}
+
+func decodePlaylistItemPageToken(sort query.PlaylistItemSort, pageToken string) (*query.PlaylistItemCursor, error) {
+	if pageToken == "" {
+		return nil, nil
+	}
+
+	var token playlistItemPageToken
+	err := decodePageToken(pageToken, &token)
+	if err != nil || token.SortField != sort.Field || token.Descending != sort.Descending {
+		return nil, errInvalidPageToken
+	}
+
+	return &query.PlaylistItemCursor{
+		ID:        token.ID,
+		Position:  token.Position,
+		CreatedAt: time.Unix(token.CreatedAt, 0).UTC(),
+	}, nil
+}
+
+// searchPageToken pages by offset since relevance order has no stable cursor
+type searchPageToken struct {
+	Offset int `json:"o"`
+}
+
+func encodeSearchPageToken(offset int) (string, error) {
+	return encodePageToken(searchPageToken{Offset: offset})
+}
+
+func decodeSearchPageToken(pageToken string) (int, error) {
+	if pageToken == "" {
+		return 0, nil
+	}
+
+	var token searchPageToken
+	err := decodePageToken(pageToken, &token)
+	if err != nil || token.Offset < 0 {
+		return 0, errInvalidPageToken
+	}
+
+	return token.Offset, nil
+}
+
+// trendingPageToken points to position of last playlist of previous page in trending ranking
+type trendingPageToken struct {
+	Position int `json:"p"`
+}
+
+func encodeTrendingPageToken(position int) (string, error) {
+	return encodePageToken(trendingPageToken{Position: position})
+}
+
+func decodeTrendingPageToken(pageToken string) (int, error) {
+	if pageToken == "" {
+		return 0, nil
+	}
+
+	var token trendingPageToken
+	err := decodePageToken(pageToken, &token)
+	if err != nil || token.Position < 0 {
+		return 0, errInvalidPageToken
+	}
+
+	return token.Position, nil
+}
+
+func encodePageToken(token interface{}) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodePageToken(pageToken string, token interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, token)
+}
+
+func playlistSort(field api.PlaylistSortField, descending bool) (query.PlaylistSort, error) {
+	var sortField query.PlaylistSortField
+	switch field {
+	case api.PlaylistSortField_CreatedAt:
+		sortField = query.PlaylistSortByCreatedAt
+	case api.PlaylistSortField_Name:
+		sortField = query.PlaylistSortByName
+	case api.PlaylistSortField_UpdatedAt:
+		sortField = query.PlaylistSortByUpdatedAt
+	case api.PlaylistSortField_ItemCount:
+		sortField = query.PlaylistSortByItemCount
+	default:
+		return query.PlaylistSort{}, status.Errorf(codes.InvalidArgument, "unknown sort field %d", field)
+	}
+
+	return query.PlaylistSort{Field: sortField, Descending: descending}, nil
+}
+
+func playlistItemSort(field api.PlaylistItemSortField, descending bool) (query.PlaylistItemSort, error) {
+	var sortField query.PlaylistItemSortField
+	switch field {
+	case api.PlaylistItemSortField_Position:
+		sortField = query.PlaylistItemSortByPosition
+	case api.PlaylistItemSortField_CreatedAt:
+		sortField = query.PlaylistItemSortByCreatedAt
+	default:
+		return query.PlaylistItemSort{}, status.Errorf(codes.InvalidArgument, "unknown sort field %d", field)
+	}
+
+	return query.PlaylistItemSort{Field: sortField, Descending: descending}, nil
+}
@@ -0,0 +1,183 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"playlistservice/pkg/playlistservice/app/query"
+)
+
+func TestPlaylistPageToken(t *testing.T) {
+	byName := query.PlaylistSort{Field: query.PlaylistSortByName}
+	cursor := query.PlaylistCursor{
+		ID:        uuid.New(),
+		Name:      "playlist",
+		CreatedAt: time.Unix(1000, 0).UTC(),
+		UpdatedAt: time.Unix(2000, 0).UTC(),
+		ItemCount: 5,
+	}
+	token, err := encodePlaylistPageToken(byName, cursor)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		sort      query.PlaylistSort
+		pageToken string
+		expected  *query.PlaylistCursor
+		err       bool
+	}{
+		{
+			name:      "first page",
+			sort:      byName,
+			pageToken: "",
+			expected:  nil,
+		},
+		{
+			name:      "same sort",
+			sort:      byName,
+			pageToken: token,
+			expected:  &cursor,
+		},
+		{
+			name:      "other sort field",
+			sort:      query.PlaylistSort{Field: query.PlaylistSortByCreatedAt},
+			pageToken: token,
+			err:       true,
+		},
+		{
+			name:      "other sort direction",
+			sort:      query.PlaylistSort{Field: query.PlaylistSortByName, Descending: true},
+			pageToken: token,
+			err:       true,
+		},
+		{
+			name:      "not base64",
+			sort:      byName,
+			pageToken: "!!!",
+			err:       true,
+		},
+		{
+			name:      "not json",
+			sort:      byName,
+			pageToken: "bm90IGpzb24",
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := decodePlaylistPageToken(test.sort, test.pageToken)
+			if test.err {
+				assert.Equal(t, errInvalidPageToken, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestPlaylistItemPageToken(t *testing.T) {
+	byCreatedAt := query.PlaylistItemSort{Field: query.PlaylistItemSortByCreatedAt, Descending: true}
+	cursor := query.PlaylistItemCursor{
+		ID:        uuid.New(),
+		Position:  42,
+		CreatedAt: time.Unix(1000, 0).UTC(),
+	}
+	token, err := encodePlaylistItemPageToken(byCreatedAt, cursor)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		sort      query.PlaylistItemSort
+		pageToken string
+		expected  *query.PlaylistItemCursor
+		err       bool
+	}{
+		{
+			name:      "first page",
+			sort:      byCreatedAt,
+			pageToken: "",
+			expected:  nil,
+		},
+		{
+			name:      "same sort",
+			sort:      byCreatedAt,
+			pageToken: token,
+			expected:  &cursor,
+		},
+		{
+			name:      "other sort field",
+			sort:      query.PlaylistItemSort{Field: query.PlaylistItemSortByPosition, Descending: true},
+			pageToken: token,
+			err:       true,
+		},
+		{
+			name:      "other sort direction",
+			sort:      query.PlaylistItemSort{Field: query.PlaylistItemSortByCreatedAt},
+			pageToken: token,
+			err:       true,
+		},
+		{
+			name:      "not base64",
+			sort:      byCreatedAt,
+			pageToken: "!!!",
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := decodePlaylistItemPageToken(test.sort, test.pageToken)
+			if test.err {
+				assert.Equal(t, errInvalidPageToken, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestOffsetPageTokens(t *testing.T) {
+	searchToken, err := encodeSearchPageToken(20)
+	assert.NoError(t, err)
+	trendingToken, err := encodeTrendingPageToken(30)
+	assert.NoError(t, err)
+	negativeSearchToken, err := encodeSearchPageToken(-1)
+	assert.NoError(t, err)
+	negativeTrendingToken, err := encodeTrendingPageToken(-1)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		decode    func(pageToken string) (int, error)
+		pageToken string
+		expected  int
+		err       bool
+	}{
+		{name: "search first page", decode: decodeSearchPageToken, pageToken: "", expected: 0},
+		{name: "search offset", decode: decodeSearchPageToken, pageToken: searchToken, expected: 20},
+		{name: "search negative offset", decode: decodeSearchPageToken, pageToken: negativeSearchToken, err: true},
+		{name: "search invalid token", decode: decodeSearchPageToken, pageToken: "!!!", err: true},
+		{name: "trending first page", decode: decodeTrendingPageToken, pageToken: "", expected: 0},
+		{name: "trending position", decode: decodeTrendingPageToken, pageToken: trendingToken, expected: 30},
+		{name: "trending negative position", decode: decodeTrendingPageToken, pageToken: negativeTrendingToken, err: true},
+		{name: "trending invalid token", decode: decodeTrendingPageToken, pageToken: "!!!", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.decode(test.pageToken)
+			if test.err {
+				assert.Equal(t, errInvalidPageToken, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package transport
+
+import (
+	"github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
+	"github.com/google/uuid"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	api "playlistservice/api/playlistservice"
+	"playlistservice/pkg/playlistservice/app/query"
+	"playlistservice/pkg/playlistservice/domain"
+	"playlistservice/pkg/playlistservice/infrastructure"
+)
+
+func NewPlaylistAdminServiceServer(container infrastructure.DependencyContainer, logger log.Logger) api.PlaylistAdminServiceServer {
+	return &playlistAdminServiceServer{
+		container: container,
+		logger:    logger,
+	}
+}
+
+type playlistAdminServiceServer struct {
+	container infrastructure.DependencyContainer
+	logger    log.Logger
+}
+
+func (server *playlistAdminServiceServer) GetPlaylist(_ context.Context, req *api.AdminGetPlaylistRequest) (*api.AdminGetPlaylistResponse, error) {
+	adminDesc, err := server.authenticateAdmin(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistID, err := uuid.Parse(req.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemSort := query.PlaylistItemSort{Field: query.PlaylistItemSortByPosition}
+	itemCursor, err := decodePlaylistItemPageToken(itemSort, req.ItemsPageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// admin reads go through query service without locks, items are paged like in ListPlaylistItems
+	queryService := server.container.PlaylistQueryService()
+
+	playlists, err := queryService.GetPlaylists(query.PlaylistSpecification{
+		PlaylistIDs: []uuid.UUID{playlistID},
+		ItemsLimit:  query.NoItems,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(playlists) == 0 {
+		return nil, status.Errorf(codes.NotFound, "playlist not found")
+	}
+
+	playlist := playlists[0]
+
+	err = authorizePlaylistView(server.container.AuthorizationPolicy(), adminDesc.UserID, playlist)
+	server.logAction(adminDesc, "get_playlist", log.Fields{"playlist_id": playlistID}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	itemSpec := query.PlaylistItemSpecification{
+		PlaylistID: playlistID,
+		Sort:       itemSort,
+		After:      itemCursor,
+		Limit:      pageSize(req.ItemsPageSize),
+	}
+
+	playlist.PlaylistItems, err = queryService.GetPlaylistItems(itemSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextItemsPageToken string
+	if len(playlist.PlaylistItems) == itemSpec.Limit {
+		nextItemsPageToken, err = encodePlaylistItemPageToken(itemSort, query.NewPlaylistItemCursor(playlist.PlaylistItems[len(playlist.PlaylistItems)-1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &api.AdminGetPlaylistResponse{
+		Playlist:           convertPlaylistViewToAPI(playlist),
+		NextItemsPageToken: nextItemsPageToken,
+	}, nil
+}
+
+func (server *playlistAdminServiceServer) GetUserPlaylists(_ context.Context, req *api.AdminGetUserPlaylistsRequest) (*api.AdminGetUserPlaylistsResponse, error) {
+	adminDesc, err := server.authenticateAdmin(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := query.PlaylistSort{Field: query.PlaylistSortByCreatedAt}
+	cursor, err := decodePlaylistPageToken(sort, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// playlists are listed without items, admin pages items of single playlist by GetPlaylist
+	spec := query.PlaylistSpecification{
+		OwnerIDs:   []uuid.UUID{userID},
+		Sort:       sort,
+		After:      cursor,
+		Limit:      pageSize(req.PageSize),
+		ItemsLimit: query.NoItems,
+	}
+
+	playlists, err := server.container.PlaylistQueryService().GetPlaylists(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// token is built from whole page, so denied playlists do not stop paging
+	var nextPageToken string
+	if len(playlists) == spec.Limit {
+		nextPageToken, err = encodePlaylistPageToken(sort, query.NewPlaylistCursor(playlists[len(playlists)-1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// playlists denied to admin are skipped like in user listing, denials are logged for review
+	result := make([]*api.Playlist, 0, len(playlists))
+	var deniedPlaylistIDs []uuid.UUID
+	for _, playlistView := range playlists {
+		if authorizePlaylistView(server.container.AuthorizationPolicy(), adminDesc.UserID, playlistView) != nil {
+			deniedPlaylistIDs = append(deniedPlaylistIDs, playlistView.ID)
+			continue
+		}
+		result = append(result, convertPlaylistViewToAPI(playlistView))
+	}
+
+	fields := log.Fields{"user_id": userID}
+	if len(deniedPlaylistIDs) != 0 {
+		fields["denied_playlist_ids"] = deniedPlaylistIDs
+	}
+	server.logAction(adminDesc, "get_user_playlists", fields, nil)
+
+	return &api.AdminGetUserPlaylistsResponse{
+		Playlists:     result,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func (server *playlistAdminServiceServer) SetPlaylistName(ctx context.Context, req *api.AdminSetPlaylistNameRequest) (*emptypb.Empty, error) {
+	adminDesc, err := server.authenticateAdmin(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistID, err := uuid.Parse(req.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := commandMetadata(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.container.PlaylistService().SetPlaylistName(playlistID, adminDesc, req.NewName, metadata)
+	server.logAction(adminDesc, "set_playlist_name", log.Fields{
+		"playlist_id": playlistID,
+		"new_name":    req.NewName,
+		"reason":      req.Reason,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *playlistAdminServiceServer) RemoveFromPlaylist(ctx context.Context, req *api.AdminRemoveFromPlaylistRequest) (*emptypb.Empty, error) {
+	adminDesc, err := server.authenticateAdmin(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistItemID, err := uuid.Parse(req.PlaylistItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := commandMetadata(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.container.PlaylistService().RemoveFromPlaylist(playlistItemID, adminDesc, metadata)
+	server.logAction(adminDesc, "remove_from_playlist", log.Fields{
+		"playlist_item_id": playlistItemID,
+		"reason":           req.Reason,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *playlistAdminServiceServer) RemovePlaylist(ctx context.Context, req *api.AdminRemovePlaylistRequest) (*emptypb.Empty, error) {
+	adminDesc, err := server.authenticateAdmin(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistID, err := uuid.Parse(req.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := commandMetadata(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.container.PlaylistService().RemovePlaylist(playlistID, adminDesc, metadata)
+	server.logAction(adminDesc, "remove_playlist", log.Fields{
+		"playlist_id": playlistID,
+		"reason":      req.Reason,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// authenticateAdmin lets through only users with global admin or moderator role,
+// permissions for each action are still checked by authorization policy
+func (server *playlistAdminServiceServer) authenticateAdmin(userToken string) (auth.UserDescriptor, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(userToken)
+	if err != nil {
+		return auth.UserDescriptor{}, err
+	}
+
+	err = server.container.AuthorizationPolicy().AuthorizeGlobalRole(domain.UserID(userDesc.UserID), domain.RoleAdmin, domain.RoleModerator)
+	if err != nil {
+		return auth.UserDescriptor{}, err
+	}
+
+	return userDesc, nil
+}
+
+func (server *playlistAdminServiceServer) logAction(adminDesc auth.UserDescriptor, action string, fields log.Fields, err error) {
+	fields["admin_id"] = adminDesc.UserID
+	fields["action"] = action
+
+	entry := server.logger.WithFields(fields)
+	if err != nil {
+		entry.Error(err, "admin action failed")
+	} else {
+		entry.Info("admin action performed")
+	}
+}
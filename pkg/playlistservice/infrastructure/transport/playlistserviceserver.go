@@ -1,7 +1,13 @@
 package transport
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/logger"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -9,20 +15,24 @@ import (
 
 	api "playlistservice/api/playlistservice"
 	"playlistservice/pkg/playlistservice/app/query"
+	"playlistservice/pkg/playlistservice/app/service"
+	"playlistservice/pkg/playlistservice/domain"
 	"playlistservice/pkg/playlistservice/infrastructure"
 )
 
-func NewPlaylistServiceServer(container infrastructure.DependencyContainer) api.PlayListServiceServer {
+func NewPlaylistServiceServer(container infrastructure.DependencyContainer, logger log.Logger) api.PlayListServiceServer {
 	return &playlistServiceServer{
 		container: container,
+		logger:    logger,
 	}
 }
 
 type playlistServiceServer struct {
 	container infrastructure.DependencyContainer
+	logger    log.Logger
 }
 
-func (server *playlistServiceServer) CreatePlaylist(_ context.Context, req *api.CreatePlaylistRequest) (*api.CreatePlaylistResponse, error) {
+func (server *playlistServiceServer) CreatePlaylist(ctx context.Context, req *api.CreatePlaylistRequest) (*api.CreatePlaylistResponse, error) {
 	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
 	if err != nil {
 		return nil, err
@@ -30,7 +40,12 @@ func (server *playlistServiceServer) CreatePlaylist(_ context.Context, req *api.
 
 	playlistService := server.container.PlaylistService()
 
-	playlistID, err := playlistService.CreatePlaylist(req.Name, userDesc)
+	metadata, err := commandMetadata(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistID, err := playlistService.CreatePlaylist(req.Name, userDesc, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +53,7 @@ func (server *playlistServiceServer) CreatePlaylist(_ context.Context, req *api.
 	return &api.CreatePlaylistResponse{PlaylistID: playlistID.String()}, nil
 }
 
-func (server *playlistServiceServer) AddToPlaylist(_ context.Context, req *api.AddToPlaylistRequest) (*api.AddToPlaylistResponse, error) {
+func (server *playlistServiceServer) AddToPlaylist(ctx context.Context, req *api.AddToPlaylistRequest) (*api.AddToPlaylistResponse, error) {
 	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
 	if err != nil {
 		return nil, err
@@ -56,7 +71,12 @@ func (server *playlistServiceServer) AddToPlaylist(_ context.Context, req *api.A
 		return nil, err
 	}
 
-	playlistItemID, err := playlistService.AddToPlaylist(playlistID, userDesc, contentID)
+	metadata, err := commandMetadata(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistItemID, err := playlistService.AddToPlaylist(playlistID, userDesc, contentID, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +86,33 @@ func (server *playlistServiceServer) AddToPlaylist(_ context.Context, req *api.A
 	}, nil
 }
 
-func (server *playlistServiceServer) SetPlaylistName(_ context.Context, req *api.SetPlaylistNameRequest) (*emptypb.Empty, error) {
+func (server *playlistServiceServer) SetPlaylistName(ctx context.Context, req *api.SetPlaylistNameRequest) (*emptypb.Empty, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistService := server.container.PlaylistService()
+
+	playlistID, err := uuid.Parse(req.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := commandMetadata(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = playlistService.SetPlaylistName(playlistID, userDesc, req.NewName, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *playlistServiceServer) SetPlaylistDiscoverable(ctx context.Context, req *api.SetPlaylistDiscoverableRequest) (*emptypb.Empty, error) {
 	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
 	if err != nil {
 		return nil, err
@@ -79,7 +125,12 @@ func (server *playlistServiceServer) SetPlaylistName(_ context.Context, req *api
 		return nil, err
 	}
 
-	err = playlistService.SetPlaylistName(playlistID, userDesc, req.NewName)
+	metadata, err := commandMetadata(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = playlistService.SetPlaylistDiscoverable(playlistID, userDesc, req.Discoverable, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +138,7 @@ func (server *playlistServiceServer) SetPlaylistName(_ context.Context, req *api
 	return &emptypb.Empty{}, nil
 }
 
-func (server *playlistServiceServer) RemoveFromPlaylist(_ context.Context, req *api.RemoveFromPlaylistRequest) (*emptypb.Empty, error) {
+func (server *playlistServiceServer) RemoveFromPlaylist(ctx context.Context, req *api.RemoveFromPlaylistRequest) (*emptypb.Empty, error) {
 	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
 	if err != nil {
 		return nil, err
@@ -100,7 +151,12 @@ func (server *playlistServiceServer) RemoveFromPlaylist(_ context.Context, req *
 		return nil, err
 	}
 
-	err = playlistService.RemoveFromPlaylist(playlistItemID, userDesc)
+	metadata, err := commandMetadata(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = playlistService.RemoveFromPlaylist(playlistItemID, userDesc, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +164,7 @@ func (server *playlistServiceServer) RemoveFromPlaylist(_ context.Context, req *
 	return &emptypb.Empty{}, nil
 }
 
-func (server *playlistServiceServer) RemovePlaylist(_ context.Context, req *api.RemovePlaylistRequest) (*emptypb.Empty, error) {
+func (server *playlistServiceServer) RemovePlaylist(ctx context.Context, req *api.RemovePlaylistRequest) (*emptypb.Empty, error) {
 	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
 	if err != nil {
 		return nil, err
@@ -121,7 +177,12 @@ func (server *playlistServiceServer) RemovePlaylist(_ context.Context, req *api.
 		return nil, err
 	}
 
-	err = playlistService.RemovePlaylist(playlistID, userDesc)
+	metadata, err := commandMetadata(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = playlistService.RemovePlaylist(playlistID, userDesc, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -129,73 +190,850 @@ func (server *playlistServiceServer) RemovePlaylist(_ context.Context, req *api.
 	return &emptypb.Empty{}, nil
 }
 
-func (server *playlistServiceServer) GetPlaylist(_ context.Context, req *api.GetPlaylistRequest) (*api.GetPlaylistResponse, error) {
+func (server *playlistServiceServer) GetPlaylist(ctx context.Context, req *api.GetPlaylistRequest) (*api.GetPlaylistResponse, error) {
 	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
 	if err != nil {
 		return nil, err
 	}
 
-	queryService := server.container.PlaylistQueryService()
-
 	playlistID, err := uuid.Parse(req.PlaylistID)
 	if err != nil {
 		return nil, err
 	}
 
+	mask, err := parsePlaylistFieldMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// version is read from write model, so ETag agrees with If-Match checked by mutating calls,
+	// items are not loaded until conditional request is known to be modified
+	current, err := findPlaylist(server.container.PlaylistQueryService(), playlistID, query.NoItems)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorizePlaylistView(server.container.AuthorizationPolicy(), userDesc.UserID, current)
+	if err != nil {
+		return nil, err
+	}
+
+	if playlistNotModified(ctx, current) {
+		err = setPlaylistVersionHeader(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		return &api.GetPlaylistResponse{}, setNotModifiedHeader(ctx)
+	}
+
+	// only full reads are counted, revalidation of cached playlist is not a read
+	countPlaylistRead(server.container.PlaylistReadCounter(), userDesc.UserID, current)
+
+	playlists, err := server.playlistsWithItems([]query.PlaylistView{current}, mask.itemsLimit())
+	if err != nil {
+		return nil, err
+	}
+	playlist := playlists[0]
+
+	err = setPlaylistVersionHeader(ctx, playlist)
+	if err != nil {
+		return nil, err
+	}
+
+	var contentPartial bool
+	playlistItems := convertPlaylistItemViewsToAPI(playlist.PlaylistItems)
+	if mask.expandsContent(req.ExpandContent) {
+		contentPartial = expandContent(ctx, server.container.ContentQueryService(), server.logger, playlistItems)
+	}
+
+	resp := &api.GetPlaylistResponse{
+		Name:                     playlist.Name,
+		OwnerID:                  playlist.OwnerID.String(),
+		CreatedAtTimestamp:       uint64(playlist.CreatedAt.Unix()),
+		UpdatedAtTimestamp:       uint64(playlist.UpdatedAt.Unix()),
+		ItemCount:                int32(playlist.ItemCount),
+		DistinctContentCount:     int32(playlist.DistinctContentCount),
+		LastItemAddedAtTimestamp: optionalTimestamp(playlist.LastItemAddedAt),
+		PlaylistItems:            playlistItems,
+		ContentPartial:           contentPartial,
+	}
+	mask.applyToGetPlaylistResponse(resp)
+
+	return resp, nil
+}
+
+// playlistsWithItems loads items of playlists read from write model, items are served from read model
+// when it caught up with version of playlist, otherwise from write model, so callers see their own writes
+func (server *playlistServiceServer) playlistsWithItems(playlists []query.PlaylistView, itemsLimit int) ([]query.PlaylistView, error) {
+	if itemsLimit == query.NoItems || len(playlists) == 0 {
+		return playlists, nil
+	}
+
+	playlistIDs := make([]uuid.UUID, len(playlists))
+	for i, playlist := range playlists {
+		playlistIDs[i] = playlist.ID
+	}
+
+	readModelPlaylists, err := server.container.PlaylistReadModelQueryService().GetPlaylists(query.PlaylistSpecification{
+		PlaylistIDs: playlistIDs,
+		ItemsLimit:  itemsLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	readModelPlaylistsMap := make(map[uuid.UUID]query.PlaylistView, len(readModelPlaylists))
+	for _, playlist := range readModelPlaylists {
+		readModelPlaylistsMap[playlist.ID] = playlist
+	}
+
+	result := make([]query.PlaylistView, len(playlists))
+	var lagging []uuid.UUID
+	for i, playlist := range playlists {
+		readModelPlaylist, ok := readModelPlaylistsMap[playlist.ID]
+		if ok && readModelPlaylist.Version == playlist.Version {
+			result[i] = readModelPlaylist
+			continue
+		}
+		result[i] = playlist
+		lagging = append(lagging, playlist.ID)
+	}
+
+	if len(lagging) == 0 {
+		return result, nil
+	}
+
+	writeModelPlaylists, err := server.container.PlaylistQueryService().GetPlaylists(query.PlaylistSpecification{
+		PlaylistIDs: lagging,
+		ItemsLimit:  itemsLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	writeModelPlaylistsMap := make(map[uuid.UUID]query.PlaylistView, len(writeModelPlaylists))
+	for _, playlist := range writeModelPlaylists {
+		writeModelPlaylistsMap[playlist.ID] = playlist
+	}
+
+	for i := range result {
+		if writeModelPlaylist, ok := writeModelPlaylistsMap[result[i].ID]; ok {
+			result[i] = writeModelPlaylist
+		}
+	}
+
+	return result, nil
+}
+
+// playlistItemsQueryService pages items from read model when it caught up with version of playlist,
+// otherwise from write model, so items agree with item count of playlist
+func (server *playlistServiceServer) playlistItemsQueryService(playlist query.PlaylistView) (query.PlaylistQueryService, error) {
+	readModelQueryService := server.container.PlaylistReadModelQueryService()
+
+	readModelPlaylist, err := findPlaylist(readModelQueryService, playlist.ID, query.NoItems)
+	if err == nil && readModelPlaylist.Version == playlist.Version {
+		return readModelQueryService, nil
+	}
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+
+	return server.container.PlaylistQueryService(), nil
+}
+
+func findPlaylist(queryService query.PlaylistQueryService, playlistID uuid.UUID, itemsLimit int) (query.PlaylistView, error) {
 	playlists, err := queryService.GetPlaylists(query.PlaylistSpecification{
-		OwnerIDs:    []uuid.UUID{userDesc.UserID},
 		PlaylistIDs: []uuid.UUID{playlistID},
+		ItemsLimit:  itemsLimit,
 	})
 	if err != nil {
-		return nil, err
+		return query.PlaylistView{}, err
 	}
 
 	if len(playlists) == 0 {
-		return nil, status.Errorf(codes.NotFound, "playlist not found")
+		return query.PlaylistView{}, status.Errorf(codes.NotFound, "playlist not found")
 	}
 
-	playlist := playlists[0]
-
-	return &api.GetPlaylistResponse{
-		Name:               playlist.Name,
-		OwnerID:            playlist.OwnerID.String(),
-		CreatedAtTimestamp: uint64(playlist.CreatedAt.Unix()),
-		UpdatedAtTimestamp: uint64(playlist.UpdatedAt.Unix()),
-		PlaylistItems:      convertPlaylistItemViewsToAPI(playlist.PlaylistItems),
-	}, nil
+	return playlists[0], nil
 }
 
-func (server *playlistServiceServer) GetUserPlaylists(_ context.Context, req *api.GetUserPlaylistsRequest) (*api.GetUserPlaylistsResponse, error) {
+func (server *playlistServiceServer) GetUserPlaylists(ctx context.Context, req *api.GetUserPlaylistsRequest) (*api.GetUserPlaylistsResponse, error) {
 	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
 	if err != nil {
 		return nil, err
 	}
 
+	// page and total count are read from write model, so created and removed playlists are listed right away,
+	// items are served from read model when it caught up with playlist
 	queryService := server.container.PlaylistQueryService()
 
-	playlists, err := queryService.GetPlaylists(query.PlaylistSpecification{OwnerIDs: []uuid.UUID{userDesc.UserID}})
+	sort, err := playlistSort(req.SortBy, req.SortDescending)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodePlaylistPageToken(sort, req.PageToken)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]*api.Playlist, len(playlists))
-	for i, playlistView := range playlists {
-		result[i] = convertPlaylistViewToAPI(playlistView)
+	mask, err := parsePlaylistFieldMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := server.container.AuthorizationPolicy()
+	sharedPlaylistIDs := policy.AssignedPlaylistIDs(domain.UserID(userDesc.UserID), domain.ActionViewPlaylist)
+
+	// playlists shared with user by roles permitting view are listed with own ones,
+	// so page, page token and total count are built from the same set of playlists
+	spec := query.PlaylistSpecification{
+		OwnerIDs:          []uuid.UUID{userDesc.UserID},
+		SharedPlaylistIDs: make([]uuid.UUID, len(sharedPlaylistIDs)),
+		Sort:              sort,
+		After:             cursor,
+		Limit:             pageSize(req.PageSize),
+		ItemsLimit:        query.NoItems,
+	}
+	for i, playlistID := range sharedPlaylistIDs {
+		spec.SharedPlaylistIDs[i] = uuid.UUID(playlistID)
+	}
+
+	playlists, err := queryService.GetPlaylists(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := queryService.CountPlaylists(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextPageToken string
+	if len(playlists) == spec.Limit {
+		nextPageToken, err = encodePlaylistPageToken(sort, query.NewPlaylistCursor(playlists[len(playlists)-1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	playlists, err = server.playlistsWithItems(playlists, mask.itemsLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*api.Playlist, 0, len(playlists))
+	for _, playlistView := range playlists {
+		result = append(result, convertPlaylistViewToAPI(playlistView))
+	}
+
+	var contentPartial bool
+	if mask.expandsContent(req.ExpandContent) {
+		var playlistItems []*api.PlaylistItem
+		for _, playlist := range result {
+			playlistItems = append(playlistItems, playlist.PlaylistItems...)
+		}
+		contentPartial = expandContent(ctx, server.container.ContentQueryService(), server.logger, playlistItems)
+	}
+
+	for _, playlist := range result {
+		mask.applyToPlaylist(playlist)
 	}
 
 	return &api.GetUserPlaylistsResponse{
-		Playlists: result,
+		Playlists:      result,
+		NextPageToken:  nextPageToken,
+		TotalCount:     int32(totalCount),
+		ContentPartial: contentPartial,
+	}, nil
+}
+
+func (server *playlistServiceServer) ListPlaylistItems(ctx context.Context, req *api.ListPlaylistItemsRequest) (*api.ListPlaylistItemsResponse, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistID, err := uuid.Parse(req.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort, err := playlistItemSort(req.SortBy, req.SortDescending)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodePlaylistItemPageToken(sort, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findPlaylist(server.container.PlaylistQueryService(), playlistID, query.NoItems)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorizePlaylistView(server.container.AuthorizationPolicy(), userDesc.UserID, current)
+	if err != nil {
+		return nil, err
+	}
+
+	queryService, err := server.playlistItemsQueryService(current)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := query.PlaylistItemSpecification{
+		PlaylistID: playlistID,
+		Sort:       sort,
+		After:      cursor,
+		Limit:      pageSize(req.PageSize),
+	}
+
+	items, err := queryService.GetPlaylistItems(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextPageToken string
+	if len(items) == spec.Limit {
+		nextPageToken, err = encodePlaylistItemPageToken(sort, query.NewPlaylistItemCursor(items[len(items)-1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var contentPartial bool
+	playlistItems := convertPlaylistItemViewsToAPI(items)
+	if req.ExpandContent {
+		contentPartial = expandContent(ctx, server.container.ContentQueryService(), server.logger, playlistItems)
+	}
+
+	return &api.ListPlaylistItemsResponse{
+		PlaylistItems:  playlistItems,
+		NextPageToken:  nextPageToken,
+		TotalCount:     int32(current.ItemCount),
+		ContentPartial: contentPartial,
 	}, nil
 }
 
+// SearchPlaylists searches only in playlists of user
+func (server *playlistServiceServer) SearchPlaylists(_ context.Context, req *api.SearchPlaylistsRequest) (*api.SearchPlaylistsResponse, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "empty search query")
+	}
+
+	offset, err := decodeSearchPageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	mask, err := parsePlaylistFieldMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := query.PlaylistSearchSpecification{
+		Text:          req.Query,
+		OwnerIDs:      []uuid.UUID{userDesc.UserID},
+		CreatedAfter:  timestampToTime(req.CreatedAfterTimestamp),
+		CreatedBefore: timestampToTime(req.CreatedBeforeTimestamp),
+		UpdatedAfter:  timestampToTime(req.UpdatedAfterTimestamp),
+		UpdatedBefore: timestampToTime(req.UpdatedBeforeTimestamp),
+		Offset:        offset,
+		Limit:         pageSize(req.PageSize),
+	}
+
+	results, err := server.container.PlaylistSearchService().SearchPlaylists(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists := make([]*api.Playlist, 0, len(results))
+	for _, searchResult := range results {
+		if authorizePlaylistView(server.container.AuthorizationPolicy(), userDesc.UserID, searchResult.Playlist) != nil {
+			continue
+		}
+		playlist := convertPlaylistViewToAPI(searchResult.Playlist)
+		mask.applyToPlaylist(playlist)
+		playlists = append(playlists, playlist)
+	}
+
+	var nextPageToken string
+	if len(results) == spec.Limit {
+		nextPageToken, err = encodeSearchPageToken(spec.Offset + len(results))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &api.SearchPlaylistsResponse{
+		Playlists:     playlists,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// GetPlaylistsContainingContent returns playlists of user containing each content,
+// aggregated counts over all playlists are available to content authors and admins
+func (server *playlistServiceServer) GetPlaylistsContainingContent(
+	ctx context.Context,
+	req *api.GetPlaylistsContainingContentRequest,
+) (*api.GetPlaylistsContainingContentResponse, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.ContentIDs) > maxContentIDsPerLookup {
+		return nil, status.Errorf(codes.InvalidArgument, "too many content ids, max is %d", maxContentIDsPerLookup)
+	}
+
+	contentIDs := make([]uuid.UUID, len(req.ContentIDs))
+	for i, id := range req.ContentIDs {
+		contentIDs[i], err = uuid.Parse(id)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid content id %s", id)
+		}
+	}
+
+	queryService := server.container.PlaylistContentQueryService()
+	result := make([]*api.ContentPlaylists, len(contentIDs))
+
+	if req.Aggregated {
+		err = authorizeContentStats(ctx, server.container, userDesc.UserID, contentIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		counts, err2 := queryService.CountPlaylistsContainingContent(contentIDs)
+		if err2 != nil {
+			return nil, err2
+		}
+
+		for i, contentID := range contentIDs {
+			result[i] = &api.ContentPlaylists{
+				ContentID:     contentID.String(),
+				PlaylistCount: int32(counts[contentID]),
+			}
+		}
+
+		return &api.GetPlaylistsContainingContentResponse{Contents: result}, nil
+	}
+
+	playlists, err := queryService.GetOwnerPlaylistsContainingContent(userDesc.UserID, contentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, contentID := range contentIDs {
+		playlistIDs := make([]string, len(playlists[contentID]))
+		for j, playlistID := range playlists[contentID] {
+			playlistIDs[j] = playlistID.String()
+		}
+
+		result[i] = &api.ContentPlaylists{
+			ContentID:     contentID.String(),
+			PlaylistIDs:   playlistIDs,
+			PlaylistCount: int32(len(playlistIDs)),
+		}
+	}
+
+	return &api.GetPlaylistsContainingContentResponse{Contents: result}, nil
+}
+
+// BatchGetPlaylists returns playlists of any owners in order of requested ids,
+// playlists which are missing or not visible to user are marked in place instead of failing whole batch
+func (server *playlistServiceServer) BatchGetPlaylists(ctx context.Context, req *api.BatchGetPlaylistsRequest) (*api.BatchGetPlaylistsResponse, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.PlaylistIDs) > maxPlaylistIDsPerBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "too many playlist ids, max is %d", maxPlaylistIDsPerBatch)
+	}
+
+	playlistIDs := make([]uuid.UUID, len(req.PlaylistIDs))
+	for i, id := range req.PlaylistIDs {
+		playlistIDs[i], err = uuid.Parse(id)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid playlist id %s", id)
+		}
+	}
+
+	mask, err := parsePlaylistFieldMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(playlistIDs) == 0 {
+		return &api.BatchGetPlaylistsResponse{}, nil
+	}
+
+	// playlists are checked against write model, so callers see their own writes, items are loaded below
+	playlists, err := server.container.PlaylistQueryService().GetPlaylists(query.PlaylistSpecification{
+		PlaylistIDs: playlistIDs,
+		ItemsLimit:  query.NoItems,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, err = server.playlistsWithItems(playlists, mask.itemsLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	playlistsMap := make(map[uuid.UUID]query.PlaylistView, len(playlists))
+	for _, playlist := range playlists {
+		playlistsMap[playlist.ID] = playlist
+	}
+
+	results := make([]*api.BatchGetPlaylistsResult, len(playlistIDs))
+	var found []*api.Playlist
+	for i, playlistID := range playlistIDs {
+		results[i] = &api.BatchGetPlaylistsResult{PlaylistID: playlistID.String()}
+
+		playlist, ok := playlistsMap[playlistID]
+		if !ok {
+			results[i].Status = api.BatchGetPlaylistsStatus_NotFound
+			continue
+		}
+
+		err = authorizePlaylistView(server.container.AuthorizationPolicy(), userDesc.UserID, playlist)
+		if errors.Is(err, domain.ErrAccessDenied) {
+			results[i].Status = api.BatchGetPlaylistsStatus_AccessDenied
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		countPlaylistRead(server.container.PlaylistReadCounter(), userDesc.UserID, playlist)
+
+		results[i].Status = api.BatchGetPlaylistsStatus_Found
+		results[i].Playlist = convertPlaylistViewToAPI(playlist)
+		found = append(found, results[i].Playlist)
+	}
+
+	var contentPartial bool
+	if mask.expandsContent(req.ExpandContent) {
+		var playlistItems []*api.PlaylistItem
+		for _, playlist := range found {
+			playlistItems = append(playlistItems, playlist.PlaylistItems...)
+		}
+		contentPartial = expandContent(ctx, server.container.ContentQueryService(), server.logger, playlistItems)
+	}
+
+	for _, playlist := range found {
+		mask.applyToPlaylist(playlist)
+	}
+
+	return &api.BatchGetPlaylistsResponse{Results: results, ContentPartial: contentPartial}, nil
+}
+
+// GetTrendingPlaylists returns ranking of playlists which owners opted in discovery, so playlists are visible to any user.
+// Ranking is refreshed periodically, pages requested across refresh may skip or repeat playlists
+func (server *playlistServiceServer) GetTrendingPlaylists(ctx context.Context, req *api.GetTrendingPlaylistsRequest) (*api.GetTrendingPlaylistsResponse, error) {
+	_, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var contentType *query.ContentType
+	if req.ContentType != nil {
+		switch *req.ContentType {
+		case api.ContentType_Song, api.ContentType_Podcast:
+			queryContentType := query.ContentType(*req.ContentType)
+			contentType = &queryContentType
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown content type %d", *req.ContentType)
+		}
+	}
+
+	afterPosition, err := decodeTrendingPageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	mask, err := parsePlaylistFieldMask(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := query.TrendingPlaylistSpecification{
+		ContentType:   contentType,
+		AfterPosition: afterPosition,
+		Limit:         pageSize(req.PageSize),
+		ItemsLimit:    mask.itemsLimit(),
+	}
+
+	trendingPlaylists, err := server.container.TrendingPlaylistQueryService().GetTrendingPlaylists(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextPageToken string
+	if len(trendingPlaylists) == spec.Limit {
+		nextPageToken, err = encodeTrendingPageToken(trendingPlaylists[len(trendingPlaylists)-1].Position)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]*api.TrendingPlaylist, len(trendingPlaylists))
+	for i, trendingPlaylist := range trendingPlaylists {
+		result[i] = &api.TrendingPlaylist{
+			Playlist: convertPlaylistViewToAPI(trendingPlaylist.Playlist),
+			Position: int32(trendingPlaylist.Position),
+			Score:    trendingPlaylist.Score,
+		}
+	}
+
+	var contentPartial bool
+	if mask.expandsContent(req.ExpandContent) {
+		var playlistItems []*api.PlaylistItem
+		for _, trendingPlaylist := range result {
+			playlistItems = append(playlistItems, trendingPlaylist.Playlist.PlaylistItems...)
+		}
+		contentPartial = expandContent(ctx, server.container.ContentQueryService(), server.logger, playlistItems)
+	}
+
+	for _, trendingPlaylist := range result {
+		mask.applyToPlaylist(trendingPlaylist.Playlist)
+	}
+
+	return &api.GetTrendingPlaylistsResponse{
+		Playlists:      result,
+		NextPageToken:  nextPageToken,
+		ContentPartial: contentPartial,
+	}, nil
+}
+
+func (server *playlistServiceServer) GetPlaylistAuditLog(_ context.Context, req *api.GetPlaylistAuditLogRequest) (*api.GetPlaylistAuditLogResponse, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistID, err := uuid.Parse(req.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.authorizeAuditLog(userDesc.UserID, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := query.AuditLogSpecification{
+		PlaylistID: playlistID,
+		Limit:      pageSize(req.PageSize),
+	}
+
+	if req.PageToken != "" {
+		afterID, err2 := strconv.ParseInt(req.PageToken, 10, 64)
+		if err2 != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token")
+		}
+		spec.AfterID = &afterID
+	}
+
+	records, err := server.container.AuditLogQueryService().GetAuditLog(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*api.AuditRecord, len(records))
+	for i, record := range records {
+		result[i] = &api.AuditRecord{
+			ActorID:            record.ActorID.String(),
+			Command:            record.Command,
+			Diff:               record.Diff,
+			RequestID:          record.RequestID,
+			SourceIP:           record.SourceIP,
+			UserAgent:          record.UserAgent,
+			CreatedAtTimestamp: uint64(record.CreatedAt.Unix()),
+		}
+	}
+
+	var nextPageToken string
+	if len(records) == spec.Limit {
+		nextPageToken = strconv.FormatInt(records[len(records)-1].ID, 10)
+	}
+
+	return &api.GetPlaylistAuditLogResponse{
+		Records:       result,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// authorizeAuditLog checks access to playlist before audit log is read,
+// audit log of removed playlist is available only to admins
+func (server *playlistServiceServer) authorizeAuditLog(userID, playlistID uuid.UUID) error {
+	policy := server.container.AuthorizationPolicy()
+
+	playlists, err := server.container.PlaylistQueryService().GetPlaylists(query.PlaylistSpecification{
+		PlaylistIDs: []uuid.UUID{playlistID},
+		ItemsLimit:  query.NoItems,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(playlists) == 0 {
+		return policy.AuthorizeGlobalRole(domain.UserID(userID), domain.RoleAdmin)
+	}
+
+	return policy.Authorize(domain.UserID(userID), domain.ActionViewAuditLog, domain.AuthorizationTarget{
+		PlaylistID: domain.PlaylistID(playlistID),
+		OwnerID:    domain.PlaylistOwnerID(playlists[0].OwnerID),
+	})
+}
+
+func (server *playlistServiceServer) RequestDataExport(_ context.Context, req *api.RequestDataExportRequest) (*api.RequestDataExportResponse, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	exportID, err := server.container.DataExportService().RequestDataExport(userDesc, req.IncludeCSV)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.RequestDataExportResponse{ExportID: exportID.String()}, nil
+}
+
+func (server *playlistServiceServer) GetDataExport(_ context.Context, req *api.GetDataExportRequest) (*api.GetDataExportResponse, error) {
+	userDesc, err := server.container.UserDescriptorSerializer().Deserialize(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	exportID, err := uuid.Parse(req.ExportID)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := server.container.DataExportService().GetDataExport(exportID, userDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &api.GetDataExportResponse{
+		ExportID:           export.ID.String(),
+		Status:             api.DataExportStatus_Pending,
+		CreatedAtTimestamp: uint64(export.CreatedAt.Unix()),
+	}
+
+	if export.Status == service.DataExportCompleted {
+		response.Status = api.DataExportStatus_Completed
+		response.DownloadURL = dataExportDownloadURL(export.ID)
+		if export.CompletedAt != nil {
+			response.CompletedAtTimestamp = uint64(export.CompletedAt.Unix())
+		}
+	}
+
+	return response, nil
+}
+
+const maxContentIDsPerLookup = 100
+
+const maxPlaylistIDsPerBatch = 100
+
+// playlistSummaryItemsCount limits items returned with playlists, rest of items are listed by ListPlaylistItems
+const playlistSummaryItemsCount = 20
+
+func pageSize(requested int32) int {
+	const (
+		defaultPageSize = 50
+		maxPageSize     = 500
+	)
+
+	if requested <= 0 {
+		return defaultPageSize
+	}
+	if requested > maxPageSize {
+		return maxPageSize
+	}
+	return int(requested)
+}
+
+// timestampToTime treats zero timestamp as not set
+func timestampToTime(timestamp uint64) *time.Time {
+	if timestamp == 0 {
+		return nil
+	}
+	t := time.Unix(int64(timestamp), 0).UTC()
+	return &t
+}
+
+// optionalTimestamp returns zero timestamp for absent time
+func optionalTimestamp(t *time.Time) uint64 {
+	if t == nil {
+		return 0
+	}
+	return uint64(t.Unix())
+}
+
+// authorizeContentStats permits admins to see stats of any content and authors to see stats of their own content
+func authorizeContentStats(ctx context.Context, container infrastructure.DependencyContainer, userID uuid.UUID, contentIDs []uuid.UUID) error {
+	if container.AuthorizationPolicy().AuthorizeGlobalRole(domain.UserID(userID), domain.RoleAdmin) == nil {
+		return nil
+	}
+
+	contents, err := container.ContentQueryService().GetContents(ctx, contentIDs)
+	if err != nil {
+		return err
+	}
+
+	authors := make(map[uuid.UUID]uuid.UUID, len(contents))
+	for _, content := range contents {
+		authors[content.ID] = content.AuthorID
+	}
+
+	for _, contentID := range contentIDs {
+		if authorID, ok := authors[contentID]; !ok || authorID != userID {
+			return status.Errorf(codes.PermissionDenied, "user %s is not author of content %s", userID, contentID)
+		}
+	}
+
+	return nil
+}
+
+// countPlaylistRead counts reads for trending ranking, owners reading own playlists do not make them trending
+func countPlaylistRead(counter service.PlaylistReadCounter, userID uuid.UUID, view query.PlaylistView) {
+	if view.OwnerID != userID {
+		counter.CountRead(userID, view.ID)
+	}
+}
+
+func authorizePlaylistView(policy domain.AuthorizationPolicy, userID uuid.UUID, view query.PlaylistView) error {
+	return policy.Authorize(domain.UserID(userID), domain.ActionViewPlaylist, domain.AuthorizationTarget{
+		PlaylistID:   domain.PlaylistID(view.ID),
+		OwnerID:      domain.PlaylistOwnerID(view.OwnerID),
+		Discoverable: view.Discoverable,
+	})
+}
+
 func convertPlaylistViewToAPI(view query.PlaylistView) *api.Playlist {
 	return &api.Playlist{
-		PlaylistID:         view.ID.String(),
-		Name:               view.Name,
-		OwnerID:            view.OwnerID.String(),
-		CreatedAtTimestamp: uint64(view.CreatedAt.Unix()),
-		UpdatedAtTimestamp: uint64(view.UpdatedAt.Unix()),
-		PlaylistItems:      convertPlaylistItemViewsToAPI(view.PlaylistItems),
+		PlaylistID:               view.ID.String(),
+		Name:                     view.Name,
+		OwnerID:                  view.OwnerID.String(),
+		CreatedAtTimestamp:       uint64(view.CreatedAt.Unix()),
+		UpdatedAtTimestamp:       uint64(view.UpdatedAt.Unix()),
+		ItemCount:                int32(view.ItemCount),
+		DistinctContentCount:     int32(view.DistinctContentCount),
+		LastItemAddedAtTimestamp: optionalTimestamp(view.LastItemAddedAt),
+		PlaylistItems:            convertPlaylistItemViewsToAPI(view.PlaylistItems),
 	}
 }
 
@@ -209,8 +1047,10 @@ func convertPlaylistItemViewsToAPI(views []query.PlaylistItemView) []*api.Playli
 
 func convertPlaylistItemViewToAPI(view query.PlaylistItemView) *api.PlaylistItem {
 	return &api.PlaylistItem{
-		PlaylistItemID:     view.ID.String(),
-		ContentID:          view.ContentID.String(),
-		CreatedAtTimestamp: uint64(view.CreatedAt.Unix()),
+		PlaylistItemID:      view.ID.String(),
+		ContentID:           view.ContentID.String(),
+		PendingVerification: view.PendingVerification,
+		Unavailable:         view.Unavailable,
+		CreatedAtTimestamp:  uint64(view.CreatedAt.Unix()),
 	}
 }
@@ -0,0 +1,167 @@
+package transport
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	commonauth "github.com/CuriosityMusicStreaming/ComponentsPool/pkg/app/auth"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	RetryAfterMetadataKey = "retry-after"
+
+	idleBucketsCleanupInterval = time.Minute
+)
+
+type RateLimit struct {
+	// Rate is amount of requests per second
+	Rate  float64
+	Burst int
+}
+
+type RateLimitConfig struct {
+	Default RateLimit
+	// Methods overrides default limit by gRPC method name, e.g. AddToPlaylist
+	Methods map[string]RateLimit
+}
+
+type userTokenRequest interface {
+	GetUserToken() string
+}
+
+// NewRateLimitServerInterceptor limits requests by user, requests without valid user token are limited by client address
+func NewRateLimitServerInterceptor(config RateLimitConfig, serializer commonauth.UserDescriptorSerializer) grpc.UnaryServerInterceptor {
+	limiter := newRateLimiter(config)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := getGRPCMethodName(info)
+
+		retryAfter, allowed := limiter.allow(rateLimitKey{client: rateLimitClient(ctx, req, serializer), method: method}, time.Now())
+		if !allowed {
+			seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+			_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterMetadataKey, seconds))
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s, retry after %s seconds", method, seconds)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func rateLimitClient(ctx context.Context, req interface{}, serializer commonauth.UserDescriptorSerializer) string {
+	if tokenRequest, ok := req.(userTokenRequest); ok {
+		userDesc, err := serializer.Deserialize(tokenRequest.GetUserToken())
+		if err == nil {
+			return "user:" + userDesc.UserID.String()
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	return "ip:" + sourceIP(ctx, md)
+}
+
+type rateLimitKey struct {
+	// client is user id or address of client without user token
+	client string
+	method string
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		buckets: map[rateLimitKey]*tokenBucket{},
+	}
+}
+
+type rateLimiter struct {
+	mutex     sync.Mutex
+	config    RateLimitConfig
+	buckets   map[rateLimitKey]*tokenBucket
+	cleanedAt time.Time
+}
+
+// allow takes token from bucket of key, when bucket is empty returns time to wait for next token
+func (limiter *rateLimiter) allow(key rateLimitKey, now time.Time) (time.Duration, bool) {
+	limit := limiter.limitFor(key.method)
+	if limit.Rate <= 0 {
+		return 0, true
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.cleanupIdleBuckets(now)
+
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updatedAt: now}
+		limiter.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		wait := (1 - bucket.tokens) / limit.Rate
+		return time.Duration(wait * float64(time.Second)), false
+	}
+
+	bucket.tokens--
+	return 0, true
+}
+
+func (limiter *rateLimiter) limitFor(method string) RateLimit {
+	limit, ok := limiter.config.Methods[method]
+	if !ok {
+		limit = limiter.config.Default
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return limit
+}
+
+// cleanupIdleBuckets drops buckets that are refilled completely since they are equal to new ones
+func (limiter *rateLimiter) cleanupIdleBuckets(now time.Time) {
+	if now.Sub(limiter.cleanedAt) < idleBucketsCleanupInterval {
+		return
+	}
+	limiter.cleanedAt = now
+
+	for key, bucket := range limiter.buckets {
+		limit := limiter.limitFor(key.method)
+		if bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+// GatewayOutgoingHeaderMatcher exposes retry-after, etag and last-modified metadata as standard http headers,
+// grpc-gateway already translates ResourceExhausted to 429 Too Many Requests
+func GatewayOutgoingHeaderMatcher(key string) (string, bool) {
+	switch key {
+	case RetryAfterMetadataKey:
+		return "Retry-After", true
+	case ETagMetadataKey:
+		return "ETag", true
+	case LastModifiedMetadataKey:
+		return "Last-Modified", true
+	case NotModifiedMetadataKey:
+		// replaced by 304 status in GatewayNotModifiedResponseOption
+		return "", false
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
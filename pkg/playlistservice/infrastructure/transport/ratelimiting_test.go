@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{
+		Default: RateLimit{Rate: 1, Burst: 2},
+		Methods: map[string]RateLimit{
+			"AddToPlaylist":  {Rate: 0.5, Burst: 1},
+			"GetPlaylist":    {Rate: 0},
+			"RemovePlaylist": {Rate: 1, Burst: 0},
+		},
+	})
+
+	now := time.Now()
+	user := rateLimitKey{client: "user:1", method: "SetPlaylistName"}
+
+	{
+		_, allowed := limiter.allow(user, now)
+		assert.True(t, allowed)
+		_, allowed = limiter.allow(user, now)
+		assert.True(t, allowed, "burst is allowed at once")
+
+		retryAfter, allowed := limiter.allow(user, now)
+		assert.False(t, allowed)
+		assert.Equal(t, time.Second, retryAfter)
+	}
+
+	{
+		retryAfter, allowed := limiter.allow(user, now.Add(500*time.Millisecond))
+		assert.False(t, allowed)
+		assert.Equal(t, 500*time.Millisecond, retryAfter, "bucket refills with rate")
+
+		_, allowed = limiter.allow(user, now.Add(time.Second))
+		assert.True(t, allowed)
+	}
+
+	{
+		_, allowed := limiter.allow(rateLimitKey{client: "user:2", method: "SetPlaylistName"}, now)
+		assert.True(t, allowed, "clients have separate buckets")
+		_, allowed = limiter.allow(rateLimitKey{client: "user:1", method: "RemoveFromPlaylist"}, now)
+		assert.True(t, allowed, "methods have separate buckets")
+	}
+
+	{
+		key := rateLimitKey{client: "ip:203.0.113.7", method: "AddToPlaylist"}
+		_, allowed := limiter.allow(key, now)
+		assert.True(t, allowed)
+
+		retryAfter, allowed := limiter.allow(key, now)
+		assert.False(t, allowed, "method burst overrides default")
+		assert.Equal(t, 2*time.Second, retryAfter, "method rate overrides default")
+	}
+
+	{
+		key := rateLimitKey{client: "user:1", method: "GetPlaylist"}
+		for i := 0; i < 10; i++ {
+			_, allowed := limiter.allow(key, now)
+			assert.True(t, allowed, "zero rate disables limit")
+		}
+	}
+
+	{
+		key := rateLimitKey{client: "user:1", method: "RemovePlaylist"}
+		_, allowed := limiter.allow(key, now)
+		assert.True(t, allowed, "burst is at least one request")
+		_, allowed = limiter.allow(key, now)
+		assert.False(t, allowed)
+	}
+}
+
+func TestRateLimiter_CleanupIdleBuckets(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{Default: RateLimit{Rate: 1, Burst: 2}})
+
+	now := time.Now()
+	idle := rateLimitKey{client: "user:1", method: "GetPlaylist"}
+	active := rateLimitKey{client: "user:2", method: "GetPlaylist"}
+
+	limiter.allow(idle, now)
+	limiter.allow(active, now)
+	assert.Len(t, limiter.buckets, 2)
+
+	later := now.Add(idleBucketsCleanupInterval)
+	limiter.allow(active, later)
+	limiter.allow(active, later)
+	limiter.allow(active, later.Add(idleBucketsCleanupInterval))
+
+	assert.Len(t, limiter.buckets, 1, "refilled buckets are dropped")
+	assert.Contains(t, limiter.buckets, active)
+}